@@ -9,6 +9,7 @@ type AppModules struct{}
 
 // GetAppModules returns the list of app modules to initialize
 // Add your generated modules here
+// Modules implementing module.Dependent are initialized after their dependencies
 func (am *AppModules) GetAppModules(deps module.Dependencies) map[string]module.Module {
 	modules := make(map[string]module.Module)
 
@@ -17,6 +18,16 @@ func (am *AppModules) GetAppModules(deps module.Dependencies) map[string]module.
 	return modules
 }
 
+// GetAppCommanders returns zero instances of the app modules implementing
+// module.Commander, so that their management commands are listed in help
+func (am *AppModules) GetAppCommanders() map[string]module.Commander {
+	commanders := make(map[string]module.Commander)
+
+	// Example: commanders["posts"] = &posts.PostsModule{}
+
+	return commanders
+}
+
 // NewAppModules creates a new AppModules provider
 func NewAppModules() *AppModules {
 	return &AppModules{}
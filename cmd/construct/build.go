@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// buildOptions holds the flags of the build command
+type buildOptions struct {
+	output  string
+	skipVue bool
+	skipGo  bool
+	noEmbed bool
+}
+
+// newBuildCommand creates the build command
+func newBuildCommand() *cobra.Command {
+	opts := &buildOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build the Vue frontend into public/ and the Go binary embedding it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBuild(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.output, "output", "o", defaultBinary, "Go binary output path")
+	cmd.Flags().BoolVar(&opts.skipVue, "skip-vue", false, "Skip the Vue build")
+	cmd.Flags().BoolVar(&opts.skipGo, "skip-go", false, "Skip the Go build")
+	cmd.Flags().BoolVar(&opts.noEmbed, "no-embed", false, "Serve public/ from disk instead of embedding it in the binary")
+
+	return cmd
+}
+
+// runBuild builds the production app
+func runBuild(opts *buildOptions) error {
+	if err := checkProjectRoot(); err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	if !opts.skipVue {
+		if !hasVue() {
+			fmt.Println("⏩ No Vue project found, skipping frontend build")
+		} else if err := buildVue(); err != nil {
+			return err
+		}
+	}
+
+	if !opts.skipGo {
+		if err := buildGo(binaryName(opts.output), !opts.noEmbed); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Build complete in %s\n", time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// buildVue builds the Vue SPA into public/, where the App serves it from
+func buildVue() error {
+	runner, err := frontendRunner()
+	if err != nil {
+		return err
+	}
+	if err := ensureFrontendDeps(runner); err != nil {
+		return err
+	}
+
+	fmt.Printf("🎨 Building Vue frontend into %s/...\n", publicDir)
+	build := command(runner, frontendScript(runner, "build", "--outDir", "../"+publicDir, "--emptyOutDir")...)
+	build.Dir = vueDir
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("vue build failed: %w", err)
+	}
+	return nil
+}
+
+// buildGo compiles the App into a binary. With embed set and a built
+// public/, the SPA is embedded so the binary can be shipped on its own.
+func buildGo(output string, embed bool) error {
+	args := []string{"build", "-o", output}
+	if _, err := os.Stat(publicDir); embed && err == nil {
+		fmt.Printf("🔨 Building Go binary %s with %s/ embedded...\n", output, publicDir)
+		args = append(args, "-tags", "embedspa")
+	} else {
+		fmt.Printf("🔨 Building Go binary %s...\n", output)
+	}
+	build := command("go", append(args, ".")...)
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("go build failed: %w", err)
+	}
+	return nil
+}
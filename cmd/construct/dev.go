@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// devOptions holds the flags of the dev command
+type devOptions struct {
+	noVue    bool
+	interval time.Duration
+}
+
+// watchSkipDirs are never scanned for Go changes
+var watchSkipDirs = map[string]bool{
+	"vue":          true,
+	"node_modules": true,
+	"public":       true,
+	"dist":         true,
+	"storage":      true,
+	"logs":         true,
+	"vendor":       true,
+}
+
+// watchFiles trigger a rebuild in addition to .go files
+var watchFiles = map[string]bool{
+	"go.mod": true,
+	"go.sum": true,
+	".env":   true,
+}
+
+// newDevCommand creates the dev command
+func newDevCommand() *cobra.Command {
+	opts := &devOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Start the Go server with rebuild on change and the Vite dev server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDev(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.noVue, "no-vue", false, "Do not start the Vite dev server")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 500*time.Millisecond, "How often to check for Go changes")
+
+	return cmd
+}
+
+// runDev runs the development servers until interrupted
+func runDev(opts *devOptions) error {
+	if err := checkProjectRoot(); err != nil {
+		return err
+	}
+	if opts.interval <= 0 {
+		opts.interval = 500 * time.Millisecond
+	}
+
+	tmpDir, err := os.MkdirTemp("", "construct-dev-")
+	if err != nil {
+		return fmt.Errorf("failed to create build directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	server := &devServer{binary: filepath.Join(tmpDir, binaryName("app"))}
+	defer server.stop()
+
+	if !opts.noVue && hasVue() {
+		vite, viteDone, err := startVite()
+		if err != nil {
+			return err
+		}
+		defer stopProcess(vite, viteDone, stopTimeout)
+
+		// Let the Go server proxy SPA requests to Vite so both ports work
+		if os.Getenv("SPA_DEV_SERVER") == "" {
+			server.extraEnv = []string{"SPA_DEV_SERVER=" + viteDevURL}
+		}
+	}
+
+	cfg := loadConfig()
+	fmt.Printf("🔧 Go server on http://localhost%s (rebuilds on change)\n", cfg.ServerPort)
+
+	if err := server.rebuild(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	snapshot := scanSources()
+	for {
+		select {
+		case <-quit:
+			fmt.Println("\n👋 Stopping development servers...")
+			return nil
+		case <-ticker.C:
+			current := scanSources()
+			if current == snapshot {
+				continue
+			}
+			snapshot = current
+
+			// Let editors finish writing before rebuilding
+			time.Sleep(opts.interval)
+			snapshot = scanSources()
+
+			fmt.Println("🔄 Changes detected, rebuilding...")
+			if err := server.rebuild(); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			}
+		}
+	}
+}
+
+// startVite starts the Vite dev server in the Vue project
+func startVite() (*exec.Cmd, <-chan error, error) {
+	runner, err := frontendRunner()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureFrontendDeps(runner); err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Println("🎨 Starting Vite dev server...")
+	vite := command(runner, frontendScript(runner, "dev")...)
+	vite.Dir = vueDir
+	vite.Stdin = nil
+	if err := vite.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start Vite: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- vite.Wait()
+	}()
+	return vite, done, nil
+}
+
+// devServer builds and runs the App, replacing the running process after
+// every successful build
+type devServer struct {
+	binary   string
+	extraEnv []string // added to the environment of the server
+	cmd      *exec.Cmd
+	done     chan error
+}
+
+// rebuild compiles the App and restarts it. If the build fails the running
+// server is kept so the previous version stays reachable.
+func (s *devServer) rebuild() error {
+	started := time.Now()
+
+	build := command("go", "build", "-o", s.binary+".next", ".")
+	build.Stdin = nil
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+
+	// Read .env on every start; the CLI's own environment still holds the
+	// values loaded at startup
+	env, err := serverEnvironment(s.extraEnv...)
+	if err != nil {
+		return err
+	}
+
+	s.stop()
+	if err := os.Rename(s.binary+".next", s.binary); err != nil {
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+
+	s.cmd = command(s.binary)
+	s.cmd.Stdin = nil
+	s.cmd.Env = env
+	if err := s.cmd.Start(); err != nil {
+		s.cmd = nil
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	s.done = make(chan error, 1)
+	go func(cmd *exec.Cmd, done chan<- error) {
+		done <- cmd.Wait()
+	}(s.cmd, s.done)
+
+	fmt.Printf("✅ Built in %s\n", time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// stop stops the running server, if any
+func (s *devServer) stop() {
+	if s.cmd == nil {
+		return
+	}
+	stopProcess(s.cmd, s.done, stopTimeout)
+	s.cmd = nil
+}
+
+// sourceSnapshot summarizes the watched files; any edit, addition or removal
+// changes it
+type sourceSnapshot struct {
+	count  int
+	latest time.Time
+	size   int64
+}
+
+// scanSources walks the project for Go sources and watched files
+func scanSources() sourceSnapshot {
+	var snap sourceSnapshot
+
+	filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		name := d.Name()
+		if d.IsDir() {
+			if path != "." && (watchSkipDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(name, ".go") && !watchFiles[name] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		snap.count++
+		snap.size += info.Size()
+		if info.ModTime().After(snap.latest) {
+			snap.latest = info.ModTime()
+		}
+		return nil
+	})
+
+	return snap
+}
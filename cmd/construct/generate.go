@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// newGenerateCommand creates the generate command
+func newGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "generate",
+		Aliases: []string{"g"},
+		Short:   "Generate code",
+	}
+
+	cmd.AddCommand(newGenerateModuleCommand())
+
+	return cmd
+}
+
+// newGenerateModuleCommand creates the generate module command
+func newGenerateModuleCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "module <name> [field:type...]",
+		Short: "Generate an app module with model, service, controller and routes",
+		Long: `Generate an app module in api/<name> and register it in api/init.go.
+
+Field types:
+  string, text, int, uint, float, decimal, bool, date, datetime
+  translation            translatable text (translation.Field)
+  attachment             file attached through ActiveStorage
+  belongs_to:<module>    foreign key to another module's model`,
+		Example: "  construct generate module posts title:string body:text author:belongs_to:users image:attachment",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateModule(args[0], args[1:], force)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite an existing module")
+
+	return cmd
+}
+
+// runGenerateModule generates and registers an app module
+func runGenerateModule(name string, fields []string, force bool) error {
+	if err := checkProjectRoot(); err != nil {
+		return err
+	}
+
+	modulePath, err := readModulePath()
+	if err != nil {
+		return err
+	}
+
+	spec, err := parseModuleSpec(modulePath, name, fields)
+	if err != nil {
+		return err
+	}
+
+	written, err := generateModule(spec, force)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Printf("   • created %s\n", path)
+	}
+
+	registered, err := registerAppModule(spec)
+	if err != nil {
+		return err
+	}
+	if registered {
+		fmt.Printf("   • registered %s in %s\n", spec.Name, appModulesFile)
+	}
+
+	fmt.Printf("✅ Module %s generated\n", spec.Name)
+	return nil
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/module/*.tmpl
+var moduleTemplates embed.FS
+
+// appModulesDir is where generated app modules are written
+const appModulesDir = "api"
+
+// appModulesFile is the provider that lists app modules
+const appModulesFile = "api/init.go"
+
+// identifierPattern validates module and field names
+var identifierPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// fieldKind describes how a field type maps to Go, GORM and validation
+type fieldKind struct {
+	goType     string
+	gormTag    string
+	binding    string
+	searchable bool
+	list       bool // included in list responses
+}
+
+// fieldKinds are the supported scalar field types
+var fieldKinds = map[string]fieldKind{
+	"string":   {goType: "string", gormTag: "size:255", binding: "max=255", searchable: true, list: true},
+	"text":     {goType: "string", gormTag: "type:text", searchable: true},
+	"int":      {goType: "int", list: true},
+	"uint":     {goType: "uint", list: true},
+	"float":    {goType: "float64", list: true},
+	"decimal":  {goType: "float64", gormTag: "type:decimal(10,2)", list: true},
+	"bool":     {goType: "bool", list: true},
+	"date":     {goType: "*time.Time", gormTag: "type:date", list: true},
+	"datetime": {goType: "*time.Time", list: true},
+}
+
+// Relation and special field types
+const (
+	kindBelongsTo   = "belongs_to"
+	kindAttachment  = "attachment"
+	kindTranslation = "translation"
+)
+
+// moduleSpec describes the module to generate
+type moduleSpec struct {
+	ModulePath string // Go module path from go.mod, e.g. "base"
+	Name       string // module key and table name, e.g. "blog_posts"
+	Package    string // e.g. "blogposts"
+	Model      string // singular type name, e.g. "BlogPost"
+	Plural     string // e.g. "BlogPosts"
+	Label      string // singular human name, e.g. "blog post"
+	Route      string // e.g. "/blog-posts"
+	Fields     []fieldSpec
+}
+
+// fieldSpec describes a single model field
+type fieldSpec struct {
+	Kind    string // scalar type name or one of the special kinds
+	Name    string // Go field name, e.g. "Author"
+	Column  string // e.g. "author"
+	GoType  string
+	GormTag string
+	Binding string
+
+	Searchable bool
+	List       bool
+
+	// belongs_to only
+	Target       string // target module, e.g. "users"
+	TargetImport string // empty for self references
+	TargetModel  string // qualified model type, e.g. "users.User"
+}
+
+// parseModuleSpec validates the generator arguments
+func parseModuleSpec(modulePath, name string, fieldArgs []string) (*moduleSpec, error) {
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid module name %q: use lowercase letters, digits and underscores", name)
+	}
+
+	singular := singularize(name)
+	spec := &moduleSpec{
+		ModulePath: modulePath,
+		Name:       name,
+		Package:    strings.ReplaceAll(name, "_", ""),
+		Model:      pascalCase(singular),
+		Plural:     pascalCase(name),
+		Label:      strings.ReplaceAll(singular, "_", " "),
+		Route:      "/" + strings.ReplaceAll(name, "_", "-"),
+	}
+
+	seen := map[string]bool{"id": true, "created_at": true, "updated_at": true, "deleted_at": true}
+	for _, arg := range fieldArgs {
+		field, err := spec.parseField(arg)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Column] {
+			return nil, fmt.Errorf("duplicate field %q", field.Column)
+		}
+		seen[field.Column] = true
+		spec.Fields = append(spec.Fields, field)
+	}
+
+	return spec, nil
+}
+
+// parseField parses a name:type[:target] field argument
+func (s *moduleSpec) parseField(arg string) (fieldSpec, error) {
+	parts := strings.Split(arg, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fieldSpec{}, fmt.Errorf("invalid field %q: expected name:type or name:belongs_to:module", arg)
+	}
+
+	column, kind := parts[0], parts[1]
+	if !identifierPattern.MatchString(column) {
+		return fieldSpec{}, fmt.Errorf("invalid field name %q", column)
+	}
+
+	field := fieldSpec{Kind: kind, Name: pascalCase(column), Column: column}
+
+	if kind != kindBelongsTo && len(parts) == 3 {
+		return fieldSpec{}, fmt.Errorf("invalid field %q: only belongs_to takes a target module", arg)
+	}
+
+	switch kind {
+	case kindBelongsTo:
+		if len(parts) != 3 {
+			return fieldSpec{}, fmt.Errorf("invalid field %q: expected name:belongs_to:module", arg)
+		}
+		if err := s.resolveTarget(&field, parts[2]); err != nil {
+			return fieldSpec{}, err
+		}
+		field.List = true
+	case kindAttachment:
+		field.GoType = "*storage.Attachment"
+	case kindTranslation:
+		field.GoType = "translation.Field"
+		field.GormTag = "type:text"
+		field.Searchable = true
+		field.List = true
+	default:
+		k, ok := fieldKinds[kind]
+		if !ok {
+			return fieldSpec{}, fmt.Errorf("unknown field type %q for %s (supported: %s)", kind, column, supportedKinds())
+		}
+		field.GoType = k.goType
+		field.GormTag = k.gormTag
+		field.Binding = k.binding
+		field.Searchable = k.searchable
+		field.List = k.list
+	}
+
+	return field, nil
+}
+
+// resolveTarget locates the module a belongs_to field references. Core modules
+// live in core/app, app modules in api.
+func (s *moduleSpec) resolveTarget(field *fieldSpec, target string) error {
+	if !identifierPattern.MatchString(target) {
+		return fmt.Errorf("invalid module name %q for %s", target, field.Column)
+	}
+
+	field.Target = target
+	model := pascalCase(singularize(target))
+	if target == s.Name {
+		field.TargetModel = model
+		return nil
+	}
+
+	pkg := strings.ReplaceAll(target, "_", "")
+	for _, dir := range []string{filepath.Join("core", "app", target), filepath.Join(appModulesDir, pkg)} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			field.TargetImport = s.ModulePath + "/" + filepath.ToSlash(dir)
+			field.TargetModel = pkg + "." + model
+			return nil
+		}
+	}
+
+	return fmt.Errorf("module %q referenced by %s not found in core/app or %s", target, field.Column, appModulesDir)
+}
+
+// Dir returns the output directory of the module
+func (s *moduleSpec) Dir() string {
+	return filepath.Join(appModulesDir, s.Package)
+}
+
+// Import returns the import path of the module
+func (s *moduleSpec) Import() string {
+	return s.ModulePath + "/" + appModulesDir + "/" + s.Package
+}
+
+// Tag returns the swagger tag of the module
+func (s *moduleSpec) Tag() string {
+	return "App/" + s.Plural
+}
+
+// Scalars returns the fields stored as columns of the model
+func (s *moduleSpec) Scalars() []fieldSpec {
+	return s.filter(func(f fieldSpec) bool {
+		return f.Kind != kindBelongsTo && f.Kind != kindAttachment
+	})
+}
+
+// Relations returns the belongs_to fields
+func (s *moduleSpec) Relations() []fieldSpec {
+	return s.filter(func(f fieldSpec) bool { return f.Kind == kindBelongsTo })
+}
+
+// Attachments returns the attachment fields
+func (s *moduleSpec) Attachments() []fieldSpec {
+	return s.filter(func(f fieldSpec) bool { return f.Kind == kindAttachment })
+}
+
+// Translated returns the translated fields
+func (s *moduleSpec) Translated() []fieldSpec {
+	return s.filter(func(f fieldSpec) bool { return f.Kind == kindTranslation })
+}
+
+// Searchable returns the fields matched by the search filter
+func (s *moduleSpec) Searchable() []fieldSpec {
+	return s.filter(func(f fieldSpec) bool { return f.Searchable })
+}
+
+// HasTime returns true if a field uses time.Time
+func (s *moduleSpec) HasTime() bool {
+	return len(s.filter(func(f fieldSpec) bool { return f.GoType == "*time.Time" })) > 0
+}
+
+// Dependencies returns the modules this module depends on
+func (s *moduleSpec) Dependencies() []string {
+	var deps []string
+	seen := make(map[string]bool)
+	for _, f := range s.Relations() {
+		if f.Target != s.Name && !seen[f.Target] {
+			seen[f.Target] = true
+			deps = append(deps, f.Target)
+		}
+	}
+	return deps
+}
+
+// RelationImports returns the import paths of referenced modules
+func (s *moduleSpec) RelationImports() []string {
+	var imports []string
+	seen := make(map[string]bool)
+	for _, f := range s.Relations() {
+		if f.TargetImport != "" && !seen[f.TargetImport] {
+			seen[f.TargetImport] = true
+			imports = append(imports, f.TargetImport)
+		}
+	}
+	return imports
+}
+
+// filter returns the fields matching the predicate
+func (s *moduleSpec) filter(match func(fieldSpec) bool) []fieldSpec {
+	var fields []fieldSpec
+	for _, f := range s.Fields {
+		if match(f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// RequestType returns the type of the field in create requests
+func (f fieldSpec) RequestType() string {
+	switch f.Kind {
+	case kindBelongsTo:
+		return "uint"
+	case kindTranslation:
+		return "string"
+	}
+	return f.GoType
+}
+
+// UpdateType returns the type of the field in update requests. Pointers
+// distinguish omitted fields from zero values.
+func (f fieldSpec) UpdateType() string {
+	t := f.RequestType()
+	if strings.HasPrefix(t, "*") {
+		return t
+	}
+	return "*" + t
+}
+
+// CreateBinding returns the binding tag of the field in create requests
+func (f fieldSpec) CreateBinding() string {
+	if f.Kind == kindBelongsTo {
+		return "required"
+	}
+	return f.Binding
+}
+
+// UpdateBinding returns the binding tag of the field in update requests
+func (f fieldSpec) UpdateBinding() string {
+	binding := strings.TrimPrefix(strings.TrimPrefix(f.Binding, "required"), ",")
+	if f.Kind == kindBelongsTo || binding == "" {
+		return ""
+	}
+	return "omitempty," + binding
+}
+
+// ModelTag returns the gorm tag of the field
+func (f fieldSpec) ModelTag() string {
+	tag := "column:" + f.Column
+	if f.GormTag != "" {
+		tag += ";" + f.GormTag
+	}
+	return tag
+}
+
+// Label returns a human readable field name
+func (f fieldSpec) Label() string {
+	return strings.ReplaceAll(f.Column, "_", " ")
+}
+
+// generateModule renders the module files and registers the module
+func generateModule(spec *moduleSpec, force bool) ([]string, error) {
+	dir := spec.Dir()
+	if _, err := os.Stat(dir); err == nil && !force {
+		return nil, fmt.Errorf("%s already exists (use --force to overwrite)", dir)
+	}
+
+	funcs := template.FuncMap{
+		"lower": strings.ToLower,
+		"join":  strings.Join,
+		"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+	}
+	tmpl, err := template.New("module").Funcs(funcs).ParseFS(moduleTemplates, "templates/module/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var written []string
+	for _, name := range []string{"model", "service", "controller", "module"} {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name+".go.tmpl", spec); err != nil {
+			return written, fmt.Errorf("failed to render %s.go: %w", name, err)
+		}
+
+		source, err := format.Source(buf.Bytes())
+		if err != nil {
+			return written, fmt.Errorf("generated %s.go is invalid: %w", name, err)
+		}
+
+		path := filepath.Join(dir, name+".go")
+		if err := os.WriteFile(path, source, 0o644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// registerAppModule adds the module to AppModules.GetAppModules in api/init.go.
+// It is a no-op if the module is already registered.
+func registerAppModule(spec *moduleSpec) (bool, error) {
+	data, err := os.ReadFile(appModulesFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", appModulesFile, err)
+	}
+	source := string(data)
+
+	entry := fmt.Sprintf("modules[%q] = %s.Init(deps)", spec.Name, spec.Package)
+	for _, line := range strings.Split(source, "\n") {
+		// The example comment does not count as a registration
+		if strings.TrimSpace(line) == entry {
+			return false, nil
+		}
+	}
+
+	// Register right before the map is returned
+	marker := "\n\treturn modules\n"
+	idx := strings.Index(source, marker)
+	if idx < 0 {
+		return false, errors.New("could not find 'return modules' in " + appModulesFile)
+	}
+	source = source[:idx] + "\t" + entry + "\n" + source[idx:]
+
+	// Add the import to the import block
+	importLine := fmt.Sprintf("%q", spec.Import())
+	if !strings.Contains(source, importLine) {
+		start := strings.Index(source, "import (")
+		if start < 0 {
+			return false, errors.New("could not find the import block in " + appModulesFile)
+		}
+		end := start + len("import (")
+		source = source[:end] + "\n\t" + importLine + source[end:]
+	}
+
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return false, fmt.Errorf("failed to format %s: %w", appModulesFile, err)
+	}
+	if err := os.WriteFile(appModulesFile, formatted, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", appModulesFile, err)
+	}
+	return true, nil
+}
+
+// readModulePath returns the module path declared in go.mod
+func readModulePath() (string, error) {
+	file, err := os.Open("go.mod")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if path, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`), nil
+		}
+	}
+	return "", errors.New("module path not found in go.mod")
+}
+
+// supportedKinds lists the supported field types for error messages
+func supportedKinds() string {
+	return "string, text, int, uint, float, decimal, bool, date, datetime, translation, attachment, belongs_to:<module>"
+}
+
+// pascalCase converts snake_case to PascalCase, keeping common initialisms upper case
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		switch part {
+		case "url", "api", "ip", "uuid", "html", "sku":
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// singularize returns the singular of the last word of a snake_case plural
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"),
+		strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return strings.TrimSuffix(s, "es")
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"):
+		return s
+	case strings.HasSuffix(s, "s"):
+		return strings.TrimSuffix(s, "s")
+	}
+	return s
+}
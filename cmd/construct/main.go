@@ -0,0 +1,46 @@
+// Command construct is the development CLI for Construct projects.
+//
+//	construct dev      # Go server with rebuild on change + Vite dev server
+//	construct build    # Vue build into public/ + Go binary
+//	construct start    # Run the production binary
+//	construct generate # Scaffold app modules
+//	construct routes   # List registered routes
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the construct command tree
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "construct",
+		Short:         "Construct Framework CLI",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Commands read the same .env as the App
+			return loadEnvironment()
+		},
+	}
+
+	root.AddCommand(
+		newDevCommand(),
+		newBuildCommand(),
+		newStartCommand(),
+		newGenerateCommand(),
+		newRoutesCommand(),
+	)
+
+	return root
+}
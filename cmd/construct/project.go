@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"base/core/config"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	// vueDir is the Vue project directory
+	vueDir = "vue"
+
+	// publicDir is where the App serves the built Vue SPA from
+	publicDir = "public"
+
+	// viteDevURL is the Vite dev server, see server.port in vue/vite.config.ts
+	viteDevURL = "http://localhost:3100"
+
+	// defaultBinary is the production binary produced by build and run by start
+	defaultBinary = "construct"
+
+	// stopTimeout bounds how long the dev server may take to exit gracefully
+	stopTimeout = 15 * time.Second
+)
+
+// inheritedEnv is the environment the CLI was started with, before .env was
+// loaded into it
+var inheritedEnv []string
+
+// loadEnvironment loads .env from the project root, exactly like the App does.
+// A missing .env is not an error.
+func loadEnvironment() error {
+	inheritedEnv = os.Environ()
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+	return nil
+}
+
+// serverEnvironment returns the environment for a freshly started App: the
+// inherited environment plus the current contents of .env, read again so
+// edits made since the CLI started take effect. As with godotenv.Load,
+// inherited variables win over .env; extra entries are appended last.
+func serverEnvironment(extra ...string) ([]string, error) {
+	dotenv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	env := append([]string{}, inheritedEnv...)
+	for key, value := range dotenv {
+		if _, inherited := lookupEnv(inheritedEnv, key); !inherited {
+			env = append(env, key+"="+value)
+		}
+	}
+	return append(env, extra...), nil
+}
+
+// lookupEnv finds key in an environment list of key=value entries
+func lookupEnv(env []string, key string) (string, bool) {
+	for _, entry := range env {
+		if name, value, ok := strings.Cut(entry, "="); ok && name == key {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// loadConfig returns the App configuration from the loaded environment
+func loadConfig() *config.Config {
+	return config.NewConfig()
+}
+
+// checkProjectRoot ensures the command runs from a Construct project root
+func checkProjectRoot() error {
+	if _, err := os.Stat("go.mod"); err != nil {
+		return errors.New("go.mod not found - run construct from the project root")
+	}
+	return nil
+}
+
+// hasVue returns true if the project has a Vue frontend
+func hasVue() bool {
+	_, err := os.Stat(filepath.Join(vueDir, "package.json"))
+	return err == nil
+}
+
+// binaryName adds the platform executable suffix
+func binaryName(name string) string {
+	if runtime.GOOS == "windows" && filepath.Ext(name) != ".exe" {
+		return name + ".exe"
+	}
+	return name
+}
+
+// frontendRunner returns the package manager used for the Vue project.
+// Bun is preferred when installed since the project ships a bun.lock.
+func frontendRunner() (string, error) {
+	if path, err := exec.LookPath("bun"); err == nil {
+		return path, nil
+	}
+	if path, err := exec.LookPath("npm"); err == nil {
+		return path, nil
+	}
+	return "", errors.New("neither bun nor npm found in PATH")
+}
+
+// frontendScript builds the arguments to run a package.json script with extra
+// arguments. npm needs "--" to forward arguments to the script, bun does not.
+func frontendScript(runner, script string, args ...string) []string {
+	cmdArgs := []string{"run", script}
+	if len(args) > 0 && isNpm(runner) {
+		cmdArgs = append(cmdArgs, "--")
+	}
+	return append(cmdArgs, args...)
+}
+
+// isNpm returns true if the runner is npm
+func isNpm(runner string) bool {
+	name := filepath.Base(runner)
+	return name == "npm" || name == "npm.cmd"
+}
+
+// ensureFrontendDeps installs the Vue dependencies if they are missing
+func ensureFrontendDeps(runner string) error {
+	if _, err := os.Stat(filepath.Join(vueDir, "node_modules")); err == nil {
+		return nil
+	}
+
+	fmt.Println("📦 Installing Vue dependencies...")
+	install := command(runner, "install")
+	install.Dir = vueDir
+	if err := install.Run(); err != nil {
+		return fmt.Errorf("failed to install Vue dependencies: %w", err)
+	}
+	return nil
+}
+
+// command creates a command attached to the terminal
+func command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// stopProcess asks a process to exit and waits for it on done, which must
+// receive the result of cmd.Wait. The process is killed if it does not exit
+// within the timeout or the platform does not support signals.
+func stopProcess(cmd *exec.Cmd, done <-chan error, timeout time.Duration) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		cmd.Process.Kill()
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		<-done
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newRoutesCommand creates the routes command
+func newRoutesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "routes [flags]",
+		Short:              "List the routes registered by the app and its modules",
+		Long:               "Runs the app's routes command. Flags are passed through, e.g. --json, --module users or --method GET.",
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkProjectRoot(); err != nil {
+				return err
+			}
+			return command("go", append([]string{"run", ".", "routes"}, args...)...).Run()
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newStartCommand creates the start command
+func newStartCommand() *cobra.Command {
+	var binary string
+
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the production server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStart(binaryName(binary))
+		},
+	}
+
+	cmd.Flags().StringVarP(&binary, "binary", "b", defaultBinary, "Go binary built by construct build")
+
+	return cmd
+}
+
+// runStart runs the production binary and forwards shutdown signals to it
+func runStart(binary string) error {
+	if _, err := os.Stat(binary); err != nil {
+		return fmt.Errorf("%s not found - run construct build first", binary)
+	}
+	if _, err := os.Stat(publicDir); err != nil {
+		fmt.Printf("⚠️  %s/ not found - the Vue app is only served if it is embedded in %s\n", publicDir, binary)
+	}
+
+	cfg := loadConfig()
+	fmt.Printf("🚀 Starting %s (env: %s, port: %s)\n", binary, cfg.Env, cfg.ServerPort)
+
+	path, err := filepath.Abs(binary)
+	if err != nil {
+		return err
+	}
+
+	server := command(path)
+	if err := server.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", binary, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Wait()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("server exited: %w", err)
+		}
+		return nil
+	case <-quit:
+		// Give the server its own drain timeout before killing it
+		stopProcess(server, done, cfg.ShutdownTimeout+5*time.Second)
+		return nil
+	}
+}
@@ -46,18 +46,18 @@ func (m *AuthenticationModule) Routes(router *router.RouterGroup) {
 }
 
 func (m *AuthenticationModule) Migrate() error {
-	if err := m.DB.AutoMigrate(&AuthUser{}); err != nil {
-		return err
-	}
+	return m.DB.AutoMigrate(&AuthUser{})
+}
 
-	// Seed admin user
-	return m.seedAdminUser()
+// Seed creates the default admin user
+func (m *AuthenticationModule) Seed(db *gorm.DB) error {
+	return m.seedAdminUser(db)
 }
 
-func (m *AuthenticationModule) seedAdminUser() error {
+func (m *AuthenticationModule) seedAdminUser(db *gorm.DB) error {
 	// Check if admin already exists
 	var count int64
-	m.DB.Model(&AuthUser{}).Where("email = ?", "admin@base.al").Count(&count)
+	db.Model(&AuthUser{}).Where("email = ?", "admin@base.al").Count(&count)
 
 	if count > 0 {
 		// Admin already exists, skip seeding
@@ -81,7 +81,7 @@ func (m *AuthenticationModule) seedAdminUser() error {
 		},
 	}
 
-	if err := m.DB.Create(admin).Error; err != nil {
+	if err := db.Create(admin).Error; err != nil {
 		return err
 	}
 
@@ -89,6 +89,11 @@ func (m *AuthenticationModule) seedAdminUser() error {
 	return nil
 }
 
+// DependsOn declares the modules that must be initialized first (AuthUser shares the users table)
+func (m *AuthenticationModule) DependsOn() []string {
+	return []string{"users"}
+}
+
 func (m *AuthenticationModule) GetModels() []any {
 	return []any{
 		&AuthUser{},
@@ -75,6 +75,17 @@ func GetOrganizationIdFromContext(c *router.Context) (uint64, error) {
 	return 0, ErrMissingOrganization
 }
 
+// ServiceMiddleware creates a middleware function that makes the authorization
+// service available to the authorization middleware of the routes behind it
+func ServiceMiddleware(service *AuthorizationService) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			c.Set("authorization_service", service)
+			return next(c)
+		}
+	}
+}
+
 // AuthMiddleware creates a middleware function that checks if the user has permission to access a resource
 func AuthMiddleware(resourceType string, action string) router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
@@ -51,12 +51,15 @@ func (m *AuthorizationModule) Migrate() error {
 		return err
 	}
 
-	// Seed default roles and permissions
-	if err := m.seedDefaultData(); err != nil {
+	return nil
+}
+
+// Seed creates the default roles and permissions
+func (m *AuthorizationModule) Seed(db *gorm.DB) error {
+	if err := m.seedDefaultData(db); err != nil {
 		m.Logger.Error("Failed to seed authorization data", logger.String("error", err.Error()))
 		return err
 	}
-
 	return nil
 }
 
@@ -68,8 +71,9 @@ func (m *AuthorizationModule) GetObject(foreignKey string, dbTableName string) [
 	return result
 }
 
-// seedDefaultData creates default roles and permissions if they don't exist
-func (m *AuthorizationModule) seedDefaultData() error {
+// seedDefaultData creates default roles and permissions if they don't exist.
+// It runs inside the seed transaction.
+func (m *AuthorizationModule) seedDefaultData(db *gorm.DB) error {
 	// Define default roles (for system-wide usage with 0 OrganizationId)
 	defaultRoles := []Role{
 		{
@@ -141,11 +145,8 @@ func (m *AuthorizationModule) seedDefaultData() error {
 	}
 	defaultPermissions = append(defaultPermissions, specialPermissions...)
 
-	// Start transaction with silent logger for seeding (to avoid "record not found" noise)
-	tx := m.DB.Session(&gorm.Session{Logger: gormLogger.Discard}).Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
+	// Silent logger for seeding (to avoid "record not found" noise)
+	tx := db.Session(&gorm.Session{Logger: gormLogger.Discard})
 
 	// Seed roles
 	for _, role := range defaultRoles {
@@ -153,7 +154,6 @@ func (m *AuthorizationModule) seedDefaultData() error {
 		result := tx.Where("name = ? AND is_system = ?", role.Name, role.IsSystem).First(&existingRole)
 		if result.Error != nil && result.Error.Error() == "record not found" {
 			if err := tx.Create(&role).Error; err != nil {
-				tx.Rollback()
 				return err
 			}
 		}
@@ -165,7 +165,6 @@ func (m *AuthorizationModule) seedDefaultData() error {
 		result := tx.Where("resource_type = ? AND action = ?", permission.ResourceType, permission.Action).First(&existingPermission)
 		if result.Error != nil && result.Error.Error() == "record not found" {
 			if err := tx.Create(&permission).Error; err != nil {
-				tx.Rollback()
 				return err
 			}
 		}
@@ -177,7 +176,6 @@ func (m *AuthorizationModule) seedDefaultData() error {
 		// Get all permissions
 		var allPermissions []Permission
 		if err := tx.Find(&allPermissions).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 
@@ -190,7 +188,6 @@ func (m *AuthorizationModule) seedDefaultData() error {
 					PermissionId: permission.Id,
 				}
 				if err := tx.Create(&rolePermission).Error; err != nil {
-					tx.Rollback()
 					return err
 				}
 			}
@@ -233,7 +230,6 @@ func (m *AuthorizationModule) seedDefaultData() error {
 					PermissionId: permission.Id,
 				}
 				if err := tx.Create(&rolePermission).Error; err != nil {
-					tx.Rollback()
 					return err
 				}
 			}
@@ -276,7 +272,6 @@ func (m *AuthorizationModule) seedDefaultData() error {
 					PermissionId: permission.Id,
 				}
 				if err := tx.Create(&rolePermission).Error; err != nil {
-					tx.Rollback()
 					return err
 				}
 			}
@@ -319,15 +314,13 @@ func (m *AuthorizationModule) seedDefaultData() error {
 					PermissionId: permission.Id,
 				}
 				if err := tx.Create(&rolePermission).Error; err != nil {
-					tx.Rollback()
 					return err
 				}
 			}
 		}
 	}
 
-	// Commit transaction
-	return tx.Commit().Error
+	return nil
 }
 
 func (m *AuthorizationModule) GetModels() []any {
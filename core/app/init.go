@@ -16,6 +16,7 @@ type CoreModules struct{}
 
 // GetCoreModules returns the list of core modules to initialize
 // This is the only function that needs to be updated when adding new core modules
+// Initialization order is derived from each module's DependsOn, not map order
 func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]module.Module {
 	modules := make(map[string]module.Module)
 
@@ -74,6 +75,17 @@ func (cm *CoreModules) GetCoreModules(deps module.Dependencies) map[string]modul
 	return modules
 }
 
+// GetCoreCommanders returns zero instances of the core modules contributing
+// management commands, which describe the commands for help. Keep it in sync
+// with the modules implementing module.Commander.
+func (cm *CoreModules) GetCoreCommanders() map[string]module.Commander {
+	return map[string]module.Commander{
+		"users":     &users.UsersModule{},
+		"media":     &media.MediaModule{},
+		"scheduler": &scheduler.Module{},
+	}
+}
+
 // NewCoreModules creates a new core modules provider
 func NewCoreModules() *CoreModules {
 	return &CoreModules{}
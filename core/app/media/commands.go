@@ -0,0 +1,55 @@
+package media
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Commands returns the storage management commands
+func (m *MediaModule) Commands() []*cobra.Command {
+	return []*cobra.Command{
+		m.storageGCCommand(),
+	}
+}
+
+// storageGCCommand removes attachments that no record references anymore
+func (m *MediaModule) storageGCCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "storage:gc",
+		Short: "Delete orphaned attachments and their files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := m.ActiveStorage.GarbageCollect(dryRun)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			for _, attachment := range result.Orphans {
+				fmt.Fprintf(out, "orphan  #%d  %s/%d.%s  %s\n", attachment.Id, attachment.ModelType, attachment.ModelId, attachment.Field, attachment.Path)
+			}
+			for _, gcErr := range result.Errors {
+				fmt.Fprintf(out, "error   %v\n", gcErr)
+			}
+
+			action := "deleted"
+			if dryRun {
+				action = "found (dry run)"
+			}
+			fmt.Fprintf(out, "Scanned %d attachment(s): %d orphan(s) %s, %d skipped\n",
+				result.Scanned, len(result.Orphans)-len(result.Errors), action, result.Skipped)
+
+			if len(result.Errors) > 0 {
+				return fmt.Errorf("%d attachment(s) could not be deleted", len(result.Errors))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list orphaned attachments")
+
+	return cmd
+}
@@ -129,7 +129,7 @@ type CreateFolderRequest struct {
 
 // ShareMediaRequest represents the request payload for sharing media
 type ShareMediaRequest struct {
-	MediaId     uint     `json:"media_id" binding:"required"`
+	MediaId     uint     `json:"media_id"` // Set from the path
 	UserIds     []uint   `json:"user_ids"`
 	RoleIds     []uint   `json:"role_ids"`
 	Permissions []string `json:"permissions" binding:"required"` // e.g., ["read", "update"]
@@ -52,6 +52,11 @@ func (m *MediaModule) Migrate() error {
 	return m.DB.AutoMigrate(&Media{})
 }
 
+// ProvideServices makes the MediaService available to other modules
+func (m *MediaModule) ProvideServices(services *module.Services) error {
+	return module.ProvideValue(services, m.Service)
+}
+
 func (m *MediaModule) GetModels() []any {
 	return []any{&Media{}}
 }
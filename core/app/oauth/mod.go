@@ -45,6 +45,11 @@ func (m *OAuthModule) Migrate() error {
 	return m.DB.AutoMigrate(&AuthProvider{})
 }
 
+// DependsOn declares the modules that must be initialized first (Auth providers belong to users)
+func (m *OAuthModule) DependsOn() []string {
+	return []string{"users"}
+}
+
 func (m *OAuthModule) GetModels() []any {
 	return []any{
 		&AuthProvider{},
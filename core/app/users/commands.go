@@ -0,0 +1,74 @@
+package users
+
+import (
+	"base/core/app/authorization"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Commands returns the user management commands
+func (m *UsersModule) Commands() []*cobra.Command {
+	return []*cobra.Command{
+		m.createCommand(),
+	}
+}
+
+// createCommand creates a user from the command line, e.g. the first administrator
+func (m *UsersModule) createCommand() *cobra.Command {
+	var req CreateUserRequest
+	var role string
+
+	cmd := &cobra.Command{
+		Use:     "users:create",
+		Short:   "Create a user",
+		Example: "  base users:create --email admin@example.com --username admin --password secret123 --role Administrator",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(req.Password) < 8 {
+				return fmt.Errorf("password must be at least 8 characters")
+			}
+			if req.FirstName == "" {
+				req.FirstName = req.Username
+			}
+
+			if role != "" {
+				var r authorization.Role
+				if err := m.DB.Where("name = ?", role).First(&r).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return fmt.Errorf("role %s not found", role)
+					}
+					return fmt.Errorf("failed to get role: %w", err)
+				}
+				req.RoleId = &r.Id
+			}
+
+			user, err := m.Service.Create(&req)
+			if err != nil {
+				return err
+			}
+
+			roleName := "-"
+			if user.Role != nil {
+				roleName = user.Role.Name
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ User %s created (id: %d, role: %s)\n", user.Email, user.Id, roleName)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.Email, "email", "", "Email address")
+	cmd.Flags().StringVar(&req.Username, "username", "", "Username")
+	cmd.Flags().StringVar(&req.Password, "password", "", "Password (at least 8 characters)")
+	cmd.Flags().StringVar(&req.FirstName, "first-name", "", "First name (default: the username)")
+	cmd.Flags().StringVar(&req.LastName, "last-name", "", "Last name")
+	cmd.Flags().StringVar(&req.Phone, "phone", "", "Phone number")
+	cmd.Flags().StringVar(&role, "role", "", "Role name (default: Member)")
+	cmd.MarkFlagRequired("email")
+	cmd.MarkFlagRequired("username")
+	cmd.MarkFlagRequired("password")
+
+	return cmd
+}
@@ -25,27 +25,27 @@ func NewUserController(service *UserService, logger logger.Logger) *UserControll
 
 func (c *UserController) Routes(router *router.RouterGroup) {
 	// Main CRUD endpoints
-	router.GET("/users", c.List)
-	router.POST("/users", c.Create)
+	router.GET("/users", c.List).Named("users.index")
+	router.POST("/users", c.Create).Named("users.store")
 
 	// Specific endpoints (must come before :id routes)
 	router.GET("/users/search", c.Search)
-	router.GET("/users/role/:role_id", c.GetByRole)
+	router.GET("/users/role/:role_id<uint>", c.GetByRole)
 
 	// Profile endpoints for current user
-	router.GET("/users/me", c.GetProfile)
+	router.GET("/users/me", c.GetProfile).Named("users.me")
 	router.PUT("/users/me", c.UpdateProfile)
 	router.PUT("/users/me/avatar", c.UpdateProfileAvatar)
 	router.PUT("/users/me/password", c.UpdateProfilePassword)
 
 	// Parameterized routes (must come last)
-	router.GET("/users/:id", c.Get)
-	router.PUT("/users/:id", c.Update)
-	router.DELETE("/users/:id", c.Delete)
+	router.GET("/users/:id<uint>", c.Get).Named("users.show")
+	router.PUT("/users/:id<uint>", c.Update).Named("users.update")
+	router.DELETE("/users/:id<uint>", c.Delete).Named("users.destroy")
 
 	// Avatar management endpoints
-	router.PUT("/users/:id/avatar", c.UpdateAvatar)
-	router.DELETE("/users/:id/avatar", c.RemoveAvatar)
+	router.PUT("/users/:id<uint>/avatar", c.UpdateAvatar)
+	router.DELETE("/users/:id<uint>/avatar", c.RemoveAvatar)
 }
 
 // List godoc
@@ -53,7 +53,7 @@ func (c *UserController) Routes(router *router.RouterGroup) {
 // @Description Get a paginated list of users with optional filtering
 // @Tags Core/Users
 // @Accept json
-// @Produce json
+// @Produce json,xml,text/csv,application/x-ndjson
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Param search query string false "Search term (searches name, username, email)"
@@ -84,7 +84,7 @@ func (c *UserController) List(ctx *router.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch users"})
 	}
 
-	return ctx.JSON(http.StatusOK, result)
+	return ctx.Negotiate(http.StatusOK, result)
 }
 
 // Get godoc
@@ -102,7 +102,7 @@ func (c *UserController) List(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *UserController) Get(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := ctx.ParamUint("id")
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID format"})
 	}
@@ -116,6 +116,7 @@ func (c *UserController) Get(ctx *router.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch user"})
 	}
 
+	ctx.SetLastModified(user.UpdatedAt)
 	return ctx.JSON(http.StatusOK, user.ToResponse())
 }
 
@@ -163,7 +164,7 @@ func (c *UserController) Create(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *UserController) Update(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := ctx.ParamUint("id")
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID format"})
 	}
@@ -200,7 +201,7 @@ func (c *UserController) Update(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *UserController) Delete(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := ctx.ParamUint("id")
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID format"})
 	}
@@ -233,7 +234,7 @@ func (c *UserController) Delete(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *UserController) UpdateAvatar(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := ctx.ParamUint("id")
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID format"})
 	}
@@ -270,7 +271,7 @@ func (c *UserController) UpdateAvatar(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *UserController) RemoveAvatar(ctx *router.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	id, err := ctx.ParamUint("id")
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid ID format"})
 	}
@@ -292,7 +293,7 @@ func (c *UserController) RemoveAvatar(ctx *router.Context) error {
 // @Description Search users by name, username, or email
 // @Tags Core/Users
 // @Accept json
-// @Produce json
+// @Produce json,xml,text/csv,application/x-ndjson
 // @Param q query string true "Search query"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
@@ -329,7 +330,7 @@ func (c *UserController) Search(ctx *router.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to search users"})
 	}
 
-	return ctx.JSON(http.StatusOK, result)
+	return ctx.Negotiate(http.StatusOK, result)
 }
 
 // GetByRole godoc
@@ -337,7 +338,7 @@ func (c *UserController) Search(ctx *router.Context) error {
 // @Description Get users filtered by role ID
 // @Tags Core/Users
 // @Accept json
-// @Produce json
+// @Produce json,xml,text/csv,application/x-ndjson
 // @Param role_id path int true "Role ID"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
@@ -348,7 +349,7 @@ func (c *UserController) Search(ctx *router.Context) error {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (c *UserController) GetByRole(ctx *router.Context) error {
-	roleId, err := strconv.ParseUint(ctx.Param("role_id"), 10, 32)
+	roleId, err := ctx.ParamUint("role_id")
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid role ID format"})
 	}
@@ -374,7 +375,7 @@ func (c *UserController) GetByRole(ctx *router.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to fetch users by role"})
 	}
 
-	return ctx.JSON(http.StatusOK, result)
+	return ctx.Negotiate(http.StatusOK, result)
 }
 
 // Profile endpoints for current user
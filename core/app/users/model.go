@@ -54,12 +54,12 @@ type CreateUserRequest struct {
 }
 
 type UpdateUserRequest struct {
-	FirstName string `form:"first_name" binding:"max=255"`
-	LastName  string `form:"last_name" binding:"max=255"`
-	Username  string `form:"username" binding:"max=255"`
-	Phone     string `form:"phone" binding:"max=255"`
-	Email     string `form:"email" binding:"email,max=255"`
-	RoleId    *uint  `form:"role_id"`
+	FirstName string `json:"first_name" form:"first_name" binding:"max=255"`
+	LastName  string `json:"last_name" form:"last_name" binding:"max=255"`
+	Username  string `json:"username" form:"username" binding:"max=255"`
+	Phone     string `json:"phone" form:"phone" binding:"max=255"`
+	Email     string `json:"email" form:"email" binding:"omitempty,email,max=255"`
+	RoleId    *uint  `json:"role_id" form:"role_id"`
 }
 
 type UpdatePasswordRequest struct {
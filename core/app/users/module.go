@@ -40,7 +40,9 @@ func NewUsersModule(
 }
 
 func (m *UsersModule) Routes(router *router.RouterGroup) {
-	m.Controller.Routes(router)
+	// Version 1 is served for /api/users and /api/v1/users; register changed
+	// response shapes under router.Version("2") to keep older clients working
+	m.Controller.Routes(router.Version("1"))
 }
 
 func (m *UsersModule) Migrate() error {
@@ -52,6 +54,16 @@ func (m *UsersModule) Migrate() error {
 	return nil
 }
 
+// ProvideServices makes the UserService available to other modules
+func (m *UsersModule) ProvideServices(services *module.Services) error {
+	return module.ProvideValue(services, m.Service)
+}
+
+// DependsOn declares the modules that must be initialized first (Role foreign key)
+func (m *UsersModule) DependsOn() []string {
+	return []string{"authorization"}
+}
+
 func (m *UsersModule) GetModels() []any {
 	return []any{
 		&User{},
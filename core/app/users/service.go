@@ -260,14 +260,14 @@ func (s *UserService) UpdateAvatar(ctx context.Context, id uint, avatarFile *mul
 	}
 
 	// Just attach the new file - cleanup is handled inside Attach
-	_, err = s.activeStorage.Attach(user, "avatar", avatarFile)
+	_, err = s.activeStorage.WithContext(ctx).Attach(user, "avatar", avatarFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload avatar: %w", err)
 	}
 
 	// Update user's avatar
 	// user.Avatar = attachment
-	if err := s.db.Save(user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
@@ -277,7 +277,7 @@ func (s *UserService) UpdateAvatar(ctx context.Context, id uint, avatarFile *mul
 
 // RemoveAvatar removes a user's avatar
 func (s *UserService) RemoveAvatar(ctx context.Context, id uint) (*User, error) {
-	tx := s.db.Begin()
+	tx := s.db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
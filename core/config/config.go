@@ -18,6 +18,21 @@ const (
 	DefaultEnvironment   = "debug"
 	DefaultVersion       = "0.0.1"
 
+	// Server timeout defaults
+	DefaultServerReadTimeout     = 30 * time.Second
+	DefaultServerWriteTimeout    = 60 * time.Second
+	DefaultServerIdleTimeout     = 120 * time.Second
+	DefaultServerShutdownTimeout = 30 * time.Second
+
+	// Request limit defaults; the handler timeout stays below the write
+	// timeout so that clients still receive the 504
+	DefaultServerHandlerTimeout = 30 * time.Second
+	DefaultServerMaxBodySize    = 33554432 // 32MB
+
+	// Largest webhook body read to verify its signature, as large as the
+	// biggest GitHub payload
+	DefaultWebhookMaxBodySize = 26214400 // 25MB
+
 	// Database defaults
 	DefaultDBDriver   = "mysql"
 	DefaultDBHost     = "localhost"
@@ -54,6 +69,7 @@ const (
 type Config struct {
 	BaseURL              string
 	CDN                  string
+	SPADevServer         string
 	Env                  string
 	DBDriver             string
 	DBUser               string
@@ -67,6 +83,12 @@ type Config struct {
 	JWTSecret            string
 	ServerAddress        string
 	ServerPort           string
+	ServerReadTimeout    time.Duration
+	ServerWriteTimeout   time.Duration
+	ServerIdleTimeout    time.Duration
+	ShutdownTimeout      time.Duration
+	HandlerTimeout       time.Duration
+	MaxBodySize          int64
 	CORSAllowedOrigins   []string
 	Version              string
 	EmailProvider        string
@@ -112,6 +134,11 @@ type MiddlewareConfig struct {
 	LoggingSkipPaths  []string `json:"logging_skip_paths"`
 	RecoveryEnabled   bool     `json:"recovery_enabled"`
 	CORSEnabled       bool     `json:"cors_enabled"`
+	CompressionEnabled   bool   `json:"compression_enabled"`
+	CompressionMinLength int    `json:"compression_min_length"`
+	ETagEnabled          bool   `json:"etag_enabled"`
+	IdempotencyEnabled   bool   `json:"idempotency_enabled"`
+	IdempotencyTTL       string `json:"idempotency_ttl"`
 	
 	// Webhook-specific settings
 	WebhookPaths              []string `json:"webhook_paths"`
@@ -120,9 +147,13 @@ type MiddlewareConfig struct {
 	WebhookSignatureEnabled   bool     `json:"webhook_signature_enabled"`
 	WebhookRateLimitRequests  int      `json:"webhook_rate_limit_requests"`
 	WebhookRateLimitWindow    string   `json:"webhook_rate_limit_window"`
+	WebhookMaxBodySize        int64    `json:"webhook_max_body_size"`
 	
 	// Per-endpoint overrides
 	Overrides map[string]map[string]string `json:"overrides"`
+
+	// Rules added by modules (see AddRule)
+	rules *middlewareRules
 }
 
 // GetRateLimitDuration returns the rate limit window as time.Duration
@@ -143,121 +174,42 @@ func (m *MiddlewareConfig) GetWebhookRateLimitDuration() time.Duration {
 	return duration
 }
 
+// GetIdempotencyTTL returns how long idempotency keys are kept as time.Duration
+func (m *MiddlewareConfig) GetIdempotencyTTL() time.Duration {
+	duration, err := time.ParseDuration(m.IdempotencyTTL)
+	if err != nil || duration <= 0 {
+		return 24 * time.Hour // default to 1 day
+	}
+	return duration
+}
+
 // IsAPIKeyRequired checks if API key is required for a given path
 func (m *MiddlewareConfig) IsAPIKeyRequired(path string) bool {
-	if !m.APIKeyEnabled {
-		return false
-	}
-	
-	// Check if it's a webhook path
-	if m.isWebhookPath(path) {
-		return m.WebhookAPIKeyEnabled
-	}
-	
-	// Check global skip paths
-	for _, skipPath := range m.APIKeySkipPaths {
-		if m.pathMatches(path, skipPath) {
-			return false
-		}
-	}
-	
-	// Check per-endpoint overrides
-	for overridePath, settings := range m.Overrides {
-		if m.pathMatches(path, overridePath) {
-			if apiKeySetting, exists := settings["api_key"]; exists {
-				return apiKeySetting != "disabled"
-			}
-		}
-	}
-	
-	return true
+	required, _ := m.resolveAPIKey(path, m.matchingRules(path))
+	return required
 }
 
 // IsAuthRequired checks if authentication is required for a given path
 func (m *MiddlewareConfig) IsAuthRequired(path string) bool {
-	if !m.AuthEnabled {
-		return false
-	}
-	
-	// Check if it's a webhook path
-	if m.isWebhookPath(path) {
-		return m.WebhookAuthEnabled
-	}
-	
-	// Check global skip paths
-	for _, skipPath := range m.AuthSkipPaths {
-		if m.pathMatches(path, skipPath) {
-			return false
-		}
-	}
-	
-	// Check per-endpoint overrides
-	for overridePath, settings := range m.Overrides {
-		if m.pathMatches(path, overridePath) {
-			if authSetting, exists := settings["auth"]; exists {
-				return authSetting != "disabled"
-			}
-		}
-	}
-	
-	return true
+	required, _ := m.resolveAuth(path, m.matchingRules(path))
+	return required
 }
 
 // IsRateLimitRequired checks if rate limiting is required for a given path
 func (m *MiddlewareConfig) IsRateLimitRequired(path string) bool {
-	if !m.RateLimitEnabled {
-		return false
-	}
-	
-	// Check global skip paths
-	for _, skipPath := range m.RateLimitSkipPaths {
-		if m.pathMatches(path, skipPath) {
-			return false
-		}
-	}
-	
-	return true
+	limit, _ := m.RateLimitFor(path)
+	return limit != nil
 }
 
 // IsLoggingRequired checks if logging is required for a given path
 func (m *MiddlewareConfig) IsLoggingRequired(path string) bool {
-	if !m.LoggingEnabled {
-		return false
-	}
-	
-	// Check global skip paths
-	for _, skipPath := range m.LoggingSkipPaths {
-		if m.pathMatches(path, skipPath) {
-			return false
-		}
-	}
-	
-	return true
+	required, _ := m.resolveLogging(path, m.matchingRules(path))
+	return required
 }
 
 // isWebhookPath checks if a path is configured as a webhook path
 func (m *MiddlewareConfig) isWebhookPath(path string) bool {
-	for _, webhookPath := range m.WebhookPaths {
-		if m.pathMatches(path, webhookPath) {
-			return true
-		}
-	}
-	return false
-}
-
-// pathMatches checks if a path matches a pattern (supports wildcards)
-func (m *MiddlewareConfig) pathMatches(path, pattern string) bool {
-	if pattern == path {
-		return true
-	}
-	
-	// Handle wildcard patterns
-	if strings.HasSuffix(pattern, "/*") {
-		prefix := strings.TrimSuffix(pattern, "/*")
-		return strings.HasPrefix(path, prefix)
-	}
-	
-	return false
+	return matchAny(path, m.WebhookPaths)
 }
 
 // NewConfig returns a new Config instance with default values.
@@ -273,6 +225,7 @@ func NewConfig() *Config {
 		// Server settings
 		BaseURL:       baseURL,
 		CDN:           getEnvWithLog("CDN", ""),
+		SPADevServer:  getEnvWithLog("SPA_DEV_SERVER", ""),
 		Env:           getEnvWithLog("ENV", DefaultEnvironment),
 		ServerAddress: serverAddr,
 		ServerPort:    serverPort,
@@ -320,6 +273,7 @@ func NewConfig() *Config {
 	parseStorageExtensions(config)
 	parseIntegerValues(config)
 	parseBooleanValues(config)
+	parseDurationValues(config)
 	parseMiddlewareConfig(config)
 
 	return config
@@ -358,6 +312,9 @@ func parseIntegerValues(config *Config) {
 
 	// Storage Max Size
 	config.StorageMaxSize = parseInt64WithDefault("STORAGE_MAX_SIZE", DefaultStorageMaxSize)
+
+	// Request body limit
+	config.MaxBodySize = parseInt64WithDefault("SERVER_MAX_BODY_SIZE", DefaultServerMaxBodySize)
 }
 
 // parseBooleanValues parses all boolean configuration values
@@ -369,6 +326,20 @@ func parseBooleanValues(config *Config) {
 	config.SwaggerEnabled = parseBoolWithDefault("SWAGGER_ENABLED", DefaultSwaggerEnabled)
 }
 
+// parseDurationValues parses all duration configuration values
+func parseDurationValues(config *Config) {
+	// HTTP server timeouts
+	config.ServerReadTimeout = parseDurationWithDefault("SERVER_READ_TIMEOUT", DefaultServerReadTimeout)
+	config.ServerWriteTimeout = parseDurationWithDefault("SERVER_WRITE_TIMEOUT", DefaultServerWriteTimeout)
+	config.ServerIdleTimeout = parseDurationWithDefault("SERVER_IDLE_TIMEOUT", DefaultServerIdleTimeout)
+
+	// Graceful shutdown drain timeout
+	config.ShutdownTimeout = parseDurationWithDefault("SERVER_SHUTDOWN_TIMEOUT", DefaultServerShutdownTimeout)
+
+	// Handler deadline
+	config.HandlerTimeout = parseDurationWithDefault("SERVER_HANDLER_TIMEOUT", DefaultServerHandlerTimeout)
+}
+
 // parseMiddlewareConfig parses middleware configuration from environment variables
 func parseMiddlewareConfig(config *Config) {
 	// Parse middleware overrides JSON if provided
@@ -392,17 +363,22 @@ func parseMiddlewareConfig(config *Config) {
 	config.Middleware = MiddlewareConfig{
 		// Global middleware settings
 		APIKeyEnabled:     parseBoolWithDefault("MIDDLEWARE_API_KEY_ENABLED", true),
-		APIKeySkipPaths:   parsePathList("MIDDLEWARE_API_KEY_SKIP_PATHS", "/health,/,/docs/*,/swagger/*"),
+		APIKeySkipPaths:   parsePathList("MIDDLEWARE_API_KEY_SKIP_PATHS", "/health,/,/assets/*,/docs/*,/swagger/*"),
 		AuthEnabled:       parseBoolWithDefault("MIDDLEWARE_AUTH_ENABLED", false),
 		AuthSkipPaths:     parsePathList("MIDDLEWARE_AUTH_SKIP_PATHS", "/api/auth/login,/api/auth/register,/api/auth/forgot-password"),
 		RateLimitEnabled:  parseBoolWithDefault("MIDDLEWARE_RATE_LIMIT_ENABLED", true),
 		RateLimitRequests: parseIntWithDefault("MIDDLEWARE_RATE_LIMIT_REQUESTS", 60),
 		RateLimitWindow:   getEnvWithLog("MIDDLEWARE_RATE_LIMIT_WINDOW", "1m"),
-		RateLimitSkipPaths: parsePathList("MIDDLEWARE_RATE_LIMIT_SKIP_PATHS", "/health,/"),
+		RateLimitSkipPaths: parsePathList("MIDDLEWARE_RATE_LIMIT_SKIP_PATHS", "/health,/,/assets/*"),
 		LoggingEnabled:    parseBoolWithDefault("MIDDLEWARE_LOGGING_ENABLED", true),
 		LoggingSkipPaths:  parsePathList("MIDDLEWARE_LOGGING_SKIP_PATHS", ""),
 		RecoveryEnabled:   parseBoolWithDefault("MIDDLEWARE_RECOVERY_ENABLED", true),
 		CORSEnabled:       parseBoolWithDefault("MIDDLEWARE_CORS_ENABLED", true),
+		CompressionEnabled:   parseBoolWithDefault("MIDDLEWARE_COMPRESSION_ENABLED", true),
+		CompressionMinLength: parseIntWithDefault("MIDDLEWARE_COMPRESSION_MIN_LENGTH", 1024),
+		ETagEnabled:          parseBoolWithDefault("MIDDLEWARE_ETAG_ENABLED", true),
+		IdempotencyEnabled:   parseBoolWithDefault("MIDDLEWARE_IDEMPOTENCY_ENABLED", true),
+		IdempotencyTTL:       getEnvWithLog("MIDDLEWARE_IDEMPOTENCY_TTL", "24h"),
 		
 		// Webhook-specific settings
 		WebhookPaths:              webhookPaths,
@@ -411,9 +387,11 @@ func parseMiddlewareConfig(config *Config) {
 		WebhookSignatureEnabled:   parseBoolWithDefault("MIDDLEWARE_WEBHOOK_SIGNATURE_ENABLED", true),
 		WebhookRateLimitRequests:  parseIntWithDefault("MIDDLEWARE_WEBHOOK_RATE_LIMIT_REQUESTS", 1000),
 		WebhookRateLimitWindow:    getEnvWithLog("MIDDLEWARE_WEBHOOK_RATE_LIMIT_WINDOW", "1h"),
+		WebhookMaxBodySize:        parseInt64WithDefault("MIDDLEWARE_WEBHOOK_MAX_BODY_SIZE", DefaultWebhookMaxBodySize),
 		
 		// Per-endpoint overrides
 		Overrides: overrides,
+		rules:     &middlewareRules{},
 	}
 }
 
@@ -470,6 +448,17 @@ func parseBoolWithDefault(key string, defaultValue bool) bool {
 	return value
 }
 
+// parseDurationWithDefault parses a duration environment variable with default fallback
+func parseDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnvWithLog(key, defaultValue.String())
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		logConfigError("Invalid %s value: %s. Using default: %s", key, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
 // normalizePort ensures port starts with ":"
 func normalizePort(port string) string {
 	if port != "" && port[0] != ':' {
@@ -0,0 +1,414 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MiddlewareSettings defines middleware configuration for a specific path or module
+type MiddlewareSettings struct {
+	// APIKey controls API key requirement
+	// nil = use global setting, true = require, false = skip
+	APIKey *bool `json:"api_key,omitempty"`
+
+	// Auth controls authentication requirement
+	// nil = use global setting, true = require, false = skip
+	Auth *bool `json:"auth,omitempty"`
+
+	// RateLimit controls rate limiting
+	// nil = use global setting, config = custom rate limit (Requests <= 0 disables it)
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+
+	// Logging controls request logging
+	// nil = use global setting, true = enable, false = disable
+	Logging *bool `json:"logging,omitempty"`
+
+	// CORS controls CORS headers
+	// nil = use global setting, true = enable, false = disable
+	CORS *bool `json:"cors,omitempty"`
+
+	// WebhookSignature controls webhook signature verification
+	// nil = use global setting, config = custom webhook config
+	WebhookSignature *WebhookSignatureConfig `json:"webhook_signature,omitempty"`
+}
+
+// RateLimitConfig defines custom rate limiting configuration
+type RateLimitConfig struct {
+	// Requests per window
+	Requests int `json:"requests"`
+
+	// Window duration (e.g., "1m", "1h")
+	Window string `json:"window"`
+
+	// KeyFunc determines how to extract the rate limit key
+	// Options: "ip", "user", "api_key", or custom function name
+	KeyFunc string `json:"key_func,omitempty"`
+}
+
+// WebhookSignatureConfig defines webhook signature verification configuration
+type WebhookSignatureConfig struct {
+	// Provider name (e.g., "stripe", "github", "paypal")
+	Provider string `json:"provider"`
+
+	// Header name containing the signature
+	Header string `json:"header"`
+
+	// Secret environment variable name
+	SecretEnvVar string `json:"secret_env_var"`
+
+	// Algorithm (e.g., "sha256", "sha1")
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// MaxBodySize is the largest body read to verify the signature
+	// (default: MIDDLEWARE_WEBHOOK_MAX_BODY_SIZE)
+	MaxBodySize int64 `json:"max_body_size,omitempty"`
+}
+
+// MiddlewareRule applies module middleware settings to the paths matching
+// Pattern. Patterns are absolute paths where ":name" and "*" match one
+// segment and a trailing "/*" (or "*name") matches the rest of the path.
+type MiddlewareRule struct {
+	Pattern string `json:"pattern"`
+	Module  string `json:"module"`
+
+	// ModuleWide marks rules created from a module's Global settings; they
+	// rank below the module's path rules
+	ModuleWide bool `json:"module_wide,omitempty"`
+
+	Settings MiddlewareSettings `json:"settings"`
+}
+
+// Sources reported by EffectiveMiddleware for each decision
+const (
+	MiddlewareSourceDefault   = "default"
+	MiddlewareSourceGlobal    = "global"     // MIDDLEWARE_*_ENABLED toggles
+	MiddlewareSourceOverrides = "overrides"  // MIDDLEWARE_OVERRIDES
+	MiddlewareSourceSkipPaths = "skip_paths" // MIDDLEWARE_*_SKIP_PATHS
+	MiddlewareSourceWebhook   = "webhook"    // MIDDLEWARE_WEBHOOK_*
+	MiddlewareSourceModule    = "module"     // ConfigurableModule overrides
+)
+
+// EffectiveMiddleware describes the middleware applied to a path and which
+// setting decided each part
+type EffectiveMiddleware struct {
+	Path             string                  `json:"path"`
+	APIKey           bool                    `json:"api_key"`
+	Auth             bool                    `json:"auth"`
+	RateLimit        *RateLimitConfig        `json:"rate_limit"`
+	Logging          bool                    `json:"logging"`
+	CORS             bool                    `json:"cors"`
+	WebhookSignature *WebhookSignatureConfig `json:"webhook_signature"`
+	Sources          map[string]string       `json:"sources"`
+	Rules            []MiddlewareRule        `json:"rules,omitempty"`
+}
+
+// middlewareRules holds the rules added by modules
+type middlewareRules struct {
+	mu    sync.RWMutex
+	rules []MiddlewareRule
+}
+
+// AddRule registers module middleware settings. Precedence, highest first:
+// global toggles that disable a middleware, webhook and skip paths in the
+// order they have always been checked in, MIDDLEWARE_OVERRIDES, module path
+// rules, module-wide rules and the global default. Among rules of the same
+// kind the most specific pattern wins.
+func (m *MiddlewareConfig) AddRule(rule MiddlewareRule) {
+	if m.rules == nil {
+		m.rules = &middlewareRules{}
+	}
+	m.rules.mu.Lock()
+	defer m.rules.mu.Unlock()
+	m.rules.rules = append(m.rules.rules, rule)
+}
+
+// Rules returns the module rules registered so far
+func (m *MiddlewareConfig) Rules() []MiddlewareRule {
+	if m.rules == nil {
+		return nil
+	}
+	m.rules.mu.RLock()
+	defer m.rules.mu.RUnlock()
+	return append([]MiddlewareRule(nil), m.rules.rules...)
+}
+
+// Effective resolves every middleware setting for a path
+func (m *MiddlewareConfig) Effective(path string) EffectiveMiddleware {
+	rules := m.matchingRules(path)
+	effective := EffectiveMiddleware{
+		Path:    path,
+		Sources: make(map[string]string),
+		Rules:   rules,
+	}
+
+	effective.APIKey, effective.Sources["api_key"] = m.resolveAPIKey(path, rules)
+	effective.Auth, effective.Sources["auth"] = m.resolveAuth(path, rules)
+	effective.RateLimit, effective.Sources["rate_limit"] = m.resolveRateLimit(path, rules)
+	effective.Logging, effective.Sources["logging"] = m.resolveLogging(path, rules)
+	effective.CORS, effective.Sources["cors"] = m.resolveCORS(path, rules)
+	effective.WebhookSignature, effective.Sources["webhook_signature"] = m.resolveWebhookSignature(rules)
+
+	return effective
+}
+
+// RateLimitFor returns the rate limit applied to a path, or nil if the path
+// is not rate limited, together with the setting it came from. Paths sharing
+// a source share the same limit.
+func (m *MiddlewareConfig) RateLimitFor(path string) (*RateLimitConfig, string) {
+	return m.resolveRateLimit(path, m.matchingRules(path))
+}
+
+// IsCORSEnabled checks if CORS headers are sent for a given path
+func (m *MiddlewareConfig) IsCORSEnabled(path string) bool {
+	enabled, _ := m.resolveCORS(path, m.matchingRules(path))
+	return enabled
+}
+
+// WebhookSignatureFor returns the webhook signature verification applied to
+// a path, or nil if signatures are not verified
+func (m *MiddlewareConfig) WebhookSignatureFor(path string) *WebhookSignatureConfig {
+	signature, _ := m.resolveWebhookSignature(m.matchingRules(path))
+	return signature
+}
+
+// resolveAPIKey decides whether an API key is required
+func (m *MiddlewareConfig) resolveAPIKey(path string, rules []MiddlewareRule) (bool, string) {
+	if !m.APIKeyEnabled {
+		return false, MiddlewareSourceGlobal
+	}
+	if m.isWebhookPath(path) {
+		return m.WebhookAPIKeyEnabled, MiddlewareSourceWebhook
+	}
+	if matchAny(path, m.APIKeySkipPaths) {
+		return false, MiddlewareSourceSkipPaths
+	}
+	if value, pattern, ok := m.override(path, "api_key"); ok {
+		return value != "disabled", MiddlewareSourceOverrides + " " + pattern
+	}
+	for _, rule := range rules {
+		if rule.Settings.APIKey != nil {
+			return *rule.Settings.APIKey, rule.source()
+		}
+	}
+	return true, MiddlewareSourceDefault
+}
+
+// resolveAuth decides whether authentication is required
+func (m *MiddlewareConfig) resolveAuth(path string, rules []MiddlewareRule) (bool, string) {
+	if !m.AuthEnabled {
+		return false, MiddlewareSourceGlobal
+	}
+	if m.isWebhookPath(path) {
+		return m.WebhookAuthEnabled, MiddlewareSourceWebhook
+	}
+	if matchAny(path, m.AuthSkipPaths) {
+		return false, MiddlewareSourceSkipPaths
+	}
+	if value, pattern, ok := m.override(path, "auth"); ok {
+		return value != "disabled", MiddlewareSourceOverrides + " " + pattern
+	}
+	for _, rule := range rules {
+		if rule.Settings.Auth != nil {
+			return *rule.Settings.Auth, rule.source()
+		}
+	}
+	return true, MiddlewareSourceDefault
+}
+
+// resolveRateLimit decides the rate limit, nil meaning none
+func (m *MiddlewareConfig) resolveRateLimit(path string, rules []MiddlewareRule) (*RateLimitConfig, string) {
+	if !m.RateLimitEnabled {
+		return nil, MiddlewareSourceGlobal
+	}
+	if matchAny(path, m.RateLimitSkipPaths) {
+		return nil, MiddlewareSourceSkipPaths
+	}
+	if m.isWebhookPath(path) {
+		return &RateLimitConfig{
+			Requests: m.WebhookRateLimitRequests,
+			Window:   m.WebhookRateLimitWindow,
+			KeyFunc:  "ip",
+		}, MiddlewareSourceWebhook
+	}
+	if value, pattern, ok := m.override(path, "rate_limit"); ok && value == "disabled" {
+		return nil, MiddlewareSourceOverrides + " " + pattern
+	}
+	for _, rule := range rules {
+		if limit := rule.Settings.RateLimit; limit != nil {
+			if limit.Requests <= 0 {
+				return nil, rule.source()
+			}
+			resolved := *limit
+			if resolved.Window == "" {
+				resolved.Window = m.RateLimitWindow
+			}
+			if resolved.KeyFunc == "" {
+				resolved.KeyFunc = "ip"
+			}
+			return &resolved, rule.source()
+		}
+	}
+	return &RateLimitConfig{
+		Requests: m.RateLimitRequests,
+		Window:   m.RateLimitWindow,
+		KeyFunc:  "ip",
+	}, MiddlewareSourceDefault
+}
+
+// resolveLogging decides whether requests are logged
+func (m *MiddlewareConfig) resolveLogging(path string, rules []MiddlewareRule) (bool, string) {
+	if !m.LoggingEnabled {
+		return false, MiddlewareSourceGlobal
+	}
+	if matchAny(path, m.LoggingSkipPaths) {
+		return false, MiddlewareSourceSkipPaths
+	}
+	if value, pattern, ok := m.override(path, "logging"); ok {
+		return value != "disabled", MiddlewareSourceOverrides + " " + pattern
+	}
+	for _, rule := range rules {
+		if rule.Settings.Logging != nil {
+			return *rule.Settings.Logging, rule.source()
+		}
+	}
+	return true, MiddlewareSourceDefault
+}
+
+// resolveCORS decides whether CORS headers are sent
+func (m *MiddlewareConfig) resolveCORS(path string, rules []MiddlewareRule) (bool, string) {
+	if !m.CORSEnabled {
+		return false, MiddlewareSourceGlobal
+	}
+	if value, pattern, ok := m.override(path, "cors"); ok {
+		return value != "disabled", MiddlewareSourceOverrides + " " + pattern
+	}
+	for _, rule := range rules {
+		if rule.Settings.CORS != nil {
+			return *rule.Settings.CORS, rule.source()
+		}
+	}
+	return true, MiddlewareSourceDefault
+}
+
+// resolveWebhookSignature decides the signature verification, nil meaning
+// none. Only modules know the provider and secret of their webhooks.
+func (m *MiddlewareConfig) resolveWebhookSignature(rules []MiddlewareRule) (*WebhookSignatureConfig, string) {
+	if !m.WebhookSignatureEnabled {
+		return nil, MiddlewareSourceGlobal
+	}
+	for _, rule := range rules {
+		if rule.Settings.WebhookSignature != nil {
+			return rule.Settings.WebhookSignature, rule.source()
+		}
+	}
+	return nil, MiddlewareSourceDefault
+}
+
+// override returns the most specific MIDDLEWARE_OVERRIDES value for a setting
+func (m *MiddlewareConfig) override(path, setting string) (value, pattern string, ok bool) {
+	best := -1
+	for overridePath, settings := range m.Overrides {
+		v, exists := settings[setting]
+		if !exists || !MatchPath(overridePath, path) {
+			continue
+		}
+		// Ties are broken by pattern so the result does not depend on map order
+		score := patternSpecificity(overridePath)
+		if score > best || (score == best && overridePath < pattern) {
+			best, value, pattern, ok = score, v, overridePath, true
+		}
+	}
+	return value, pattern, ok
+}
+
+// matchingRules returns the module rules matching a path in precedence order
+func (m *MiddlewareConfig) matchingRules(path string) []MiddlewareRule {
+	if m.rules == nil {
+		return nil
+	}
+
+	m.rules.mu.RLock()
+	var matched []MiddlewareRule
+	for _, rule := range m.rules.rules {
+		if MatchPath(rule.Pattern, path) {
+			matched = append(matched, rule)
+		}
+	}
+	m.rules.mu.RUnlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].ModuleWide != matched[j].ModuleWide {
+			return !matched[i].ModuleWide
+		}
+		return patternSpecificity(matched[i].Pattern) > patternSpecificity(matched[j].Pattern)
+	})
+	return matched
+}
+
+// source describes the rule for EffectiveMiddleware.Sources
+func (r MiddlewareRule) source() string {
+	return MiddlewareSourceModule + " " + r.Module + " " + r.Pattern
+}
+
+// MatchPath checks if a path matches a pattern. ":name" and "*" match exactly
+// one segment; a trailing "/*" or "*name" matches the rest of the path,
+// including nothing ("/docs/*" matches "/docs" and "/docs/index.html").
+func MatchPath(pattern, path string) bool {
+	if pattern == path {
+		return true
+	}
+
+	patternSegments := splitPath(pattern)
+	pathSegments := splitPath(path)
+
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "*") && i == len(patternSegments)-1 {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if segment == "*" || strings.HasPrefix(segment, ":") {
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// matchAny checks if a path matches any of the patterns
+func matchAny(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternSpecificity ranks patterns: literal segments count most, single
+// segment wildcards less and a trailing wildcard not at all
+func patternSpecificity(pattern string) int {
+	score := 0
+	for _, segment := range splitPath(pattern) {
+		switch {
+		case strings.HasPrefix(segment, "*") && segment != "*":
+		case segment == "*" || strings.HasPrefix(segment, ":"):
+			score++
+		default:
+			score += 2
+		}
+	}
+	if !strings.Contains(pattern, "*") && !strings.Contains(pattern, ":") {
+		score++ // exact paths beat patterns of the same length
+	}
+	return score
+}
+
+// splitPath splits a path into its non-empty segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
@@ -44,3 +44,12 @@ func InitDB(cfg *config.Config) (*Database, error) {
 
 	return &Database{DB: DB}, nil
 }
+
+// Close closes the underlying connection pool
+func (d *Database) Close() error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database pool: %w", err)
+	}
+	return sqlDB.Close()
+}
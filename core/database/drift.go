@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// TableDrift describes the differences between a model and its live table
+type TableDrift struct {
+	Table          string   `json:"table"`
+	Model          string   `json:"model"`
+	MissingTable   bool     `json:"missing_table,omitempty"`
+	MissingColumns []string `json:"missing_columns,omitempty"`
+	ExtraColumns   []string `json:"extra_columns,omitempty"`
+	MissingIndexes []string `json:"missing_indexes,omitempty"`
+}
+
+// HasDrift returns true if the table differs from its model
+func (t TableDrift) HasDrift() bool {
+	return t.MissingTable || len(t.MissingColumns) > 0 || len(t.ExtraColumns) > 0 || len(t.MissingIndexes) > 0
+}
+
+// DriftReport is a dry-run comparison of models against the live schema
+type DriftReport struct {
+	Driver string       `json:"driver"`
+	Tables []TableDrift `json:"tables"`
+}
+
+// HasDrift returns true if any table differs from its model
+func (r *DriftReport) HasDrift() bool {
+	for _, table := range r.Tables {
+		if table.HasDrift() {
+			return true
+		}
+	}
+	return false
+}
+
+// Drift compares the given models (typically every module's GetModels) with
+// the live database schema without changing anything. Only tables that differ
+// are included in the report.
+func (m *Migrator) Drift(ctx context.Context, models ...any) (*DriftReport, error) {
+	db := m.db.WithContext(ctx)
+	migrator := db.Migrator()
+	cache := &sync.Map{}
+
+	report := &DriftReport{Driver: db.Dialector.Name()}
+	seen := make(map[string]bool)
+
+	for _, model := range models {
+		sch, err := schema.Parse(model, cache, db.NamingStrategy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		if seen[sch.Table] {
+			continue
+		}
+		seen[sch.Table] = true
+
+		drift := TableDrift{Table: sch.Table, Model: sch.Name}
+
+		if !migrator.HasTable(sch.Table) {
+			drift.MissingTable = true
+			report.Tables = append(report.Tables, drift)
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", sch.Table, err)
+		}
+
+		live := make(map[string]bool, len(columnTypes))
+		for _, column := range columnTypes {
+			live[column.Name()] = true
+		}
+
+		expected := make(map[string]bool, len(sch.DBNames))
+		for _, name := range sch.DBNames {
+			expected[name] = true
+			if !live[name] {
+				drift.MissingColumns = append(drift.MissingColumns, name)
+			}
+		}
+		for name := range live {
+			if !expected[name] {
+				drift.ExtraColumns = append(drift.ExtraColumns, name)
+			}
+		}
+
+		for _, index := range sch.ParseIndexes() {
+			if !migrator.HasIndex(model, index.Name) {
+				drift.MissingIndexes = append(drift.MissingIndexes, index.Name)
+			}
+		}
+
+		sort.Strings(drift.MissingColumns)
+		sort.Strings(drift.ExtraColumns)
+		sort.Strings(drift.MissingIndexes)
+
+		if drift.HasDrift() {
+			report.Tables = append(report.Tables, drift)
+		}
+	}
+
+	sort.Slice(report.Tables, func(i, j int) bool {
+		return report.Tables[i].Table < report.Tables[j].Table
+	})
+	return report, nil
+}
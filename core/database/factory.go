@@ -0,0 +1,360 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// factoryForeignKeyLimit caps the number of related ids loaded to pick
+// belongs-to foreign keys from
+const factoryForeignKeyLimit = 500
+
+// personTables are tables whose "name" columns hold person names
+var personTables = map[string]bool{
+	"users": true, "authors": true, "customers": true, "members": true,
+	"contacts": true, "employees": true, "people": true, "clients": true,
+}
+
+// Factory builds records of T filled with realistic fake values, for demo
+// data, seeds and tests. Values are derived from the column name and type
+// (email, first_name, title, price, ...); belongs-to foreign keys point to
+// existing related rows. States override the generated values. Password
+// and secret columns are left empty; set them, hashed, with a State.
+//
+//	users, err := database.NewFactory[users.User](db).
+//		State(func(u *users.User) { u.Password = hashedPassword }).
+//		Create(10)
+//
+//	posts, err := database.NewFactory[posts.Post](db).
+//		State(func(p *posts.Post) { p.Published = true }).
+//		Create(20)
+type Factory[T any] struct {
+	db     *gorm.DB
+	states []func(*T)
+}
+
+// NewFactory creates a factory for the model type T
+func NewFactory[T any](db *gorm.DB) *Factory[T] {
+	return &Factory[T]{db: db}
+}
+
+// State returns a copy of the factory that applies state to every record
+// after the fake values are generated
+func (f *Factory[T]) State(state func(*T)) *Factory[T] {
+	states := append(append([]func(*T){}, f.states...), state)
+	return &Factory[T]{db: f.db, states: states}
+}
+
+// Make builds count records without saving them
+func (f *Factory[T]) Make(count int) ([]*T, error) {
+	filler, err := newFakeFiller(f.db, new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*T, count)
+	for i := range items {
+		item := new(T)
+		if err := filler.fill(reflect.ValueOf(item)); err != nil {
+			return nil, err
+		}
+		for _, state := range f.states {
+			state(item)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// Create builds count records and inserts them in one transaction
+func (f *Factory[T]) Create(count int) ([]*T, error) {
+	items, err := f.Make(count)
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			if err := tx.Omit(clause.Associations).Create(item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %T records: %w", *new(T), err)
+	}
+	return items, nil
+}
+
+// Fake fills the zero-valued columns of model, a pointer to a struct, with
+// fake values. It is the untyped counterpart of Factory.Make.
+func Fake(db *gorm.DB, model any) error {
+	filler, err := newFakeFiller(db, model)
+	if err != nil {
+		return err
+	}
+	return filler.fill(reflect.ValueOf(model))
+}
+
+// CreateFakes inserts count fake records of the same type as model (e.g. one
+// of a module's GetModels) and returns them. It is the untyped counterpart of
+// Factory.Create, used where the model type is only known at runtime.
+func CreateFakes(db *gorm.DB, model any, count int) ([]any, error) {
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() != reflect.Pointer || modelType.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a pointer to a struct, got %T", model)
+	}
+
+	filler, err := newFakeFiller(db, model)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]any, 0, count)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for range count {
+			item := reflect.New(modelType.Elem())
+			if err := filler.fill(item); err != nil {
+				return err
+			}
+			if err := tx.Omit(clause.Associations).Create(item.Interface()).Error; err != nil {
+				return err
+			}
+			items = append(items, item.Interface())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s records: %w", filler.schema.Table, err)
+	}
+	return items, nil
+}
+
+// fakeFiller fills records of one model
+type fakeFiller struct {
+	db          *gorm.DB
+	schema      *schema.Schema
+	foreignKeys map[string][]any // field name -> candidate related ids
+}
+
+// newFakeFiller parses the model and loads candidate foreign keys
+func newFakeFiller(db *gorm.DB, model any) (*fakeFiller, error) {
+	sch, err := schema.Parse(model, &sync.Map{}, db.NamingStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+	}
+
+	filler := &fakeFiller{db: db, schema: sch, foreignKeys: make(map[string][]any)}
+
+	for _, rel := range sch.Relationships.BelongsTo {
+		for _, ref := range rel.References {
+			if ref.PrimaryKey == nil || ref.ForeignKey == nil || ref.ForeignKey.Schema != sch {
+				continue
+			}
+
+			var ids []any
+			err := db.Table(rel.FieldSchema.Table).
+				Limit(factoryForeignKeyLimit).
+				Pluck(ref.PrimaryKey.DBName, &ids).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to load %s ids for %s: %w", rel.FieldSchema.Table, ref.ForeignKey.Name, err)
+			}
+			// Without related rows the column keeps its zero value (or default)
+			filler.foreignKeys[ref.ForeignKey.Name] = ids
+		}
+	}
+
+	return filler, nil
+}
+
+// fill sets every zero-valued column of the record pointed to by value
+func (f *fakeFiller) fill(value reflect.Value) error {
+	ctx := context.Background()
+	record := reflect.Indirect(value)
+	person := newFakePerson()
+
+	for _, field := range f.schema.Fields {
+		if !f.fillable(field) {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, record); !zero {
+			continue
+		}
+
+		if ids, ok := f.foreignKeys[field.Name]; ok {
+			if len(ids) > 0 {
+				if err := field.Set(ctx, record, ids[rand.IntN(len(ids))]); err != nil {
+					return fmt.Errorf("failed to set %s: %w", field.Name, err)
+				}
+			}
+			continue
+		}
+
+		// Custom text types such as translation.Field
+		if setter, ok := field.ReflectValueOf(ctx, record).Addr().Interface().(interface{ SetOriginal(string) }); ok {
+			setter.SetOriginal(f.text(field, person))
+			continue
+		}
+
+		fake, ok := f.value(field, person)
+		if !ok {
+			continue
+		}
+		if err := field.Set(ctx, record, fake); err != nil {
+			return fmt.Errorf("failed to set %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// fillable returns true for columns the factory should generate
+func (f *fakeFiller) fillable(field *schema.Field) bool {
+	switch {
+	case field.DBName == "" || !field.Creatable:
+		return false
+	case field.PrimaryKey && (field.AutoIncrement || field.DataType == schema.Uint || field.DataType == schema.Int):
+		return false
+	case field.AutoCreateTime != 0 || field.AutoUpdateTime != 0:
+		return false
+	case field.Name == "CreatedAt" || field.Name == "UpdatedAt" || field.Name == "DeletedAt":
+		return false
+	case hasAny(strings.ToLower(field.DBName), "password", "secret"):
+		// Stored hashed; a plaintext default would never authenticate
+		return false
+	}
+	return true
+}
+
+// value returns a fake value for a column based on its name and data type
+func (f *fakeFiller) value(field *schema.Field, person fakePerson) (any, bool) {
+	name := strings.ToLower(field.DBName)
+
+	switch field.DataType {
+	case schema.String:
+		value := f.text(field, person)
+		// Person based values carry the person suffix; random ones are unique enough
+		random := hasAny(name, "phone", "mobile", "fax", "slug", "token", "code", "sku", "reference", "image", "avatar", "photo")
+		if field.Unique && !random && !strings.Contains(value, fmt.Sprint(person.suffix)) {
+			value = fmt.Sprintf("%s-%d", value, rand.IntN(1000000))
+		}
+		if field.Size > 0 && len(value) > field.Size {
+			value = value[:field.Size]
+		}
+		return value, true
+
+	case schema.Int, schema.Uint:
+		switch {
+		case name == "age" || strings.HasSuffix(name, "_age"):
+			return 18 + rand.IntN(62), true
+		case strings.Contains(name, "year"):
+			return 1990 + rand.IntN(36), true
+		case strings.Contains(name, "rating"), strings.Contains(name, "stars"):
+			return 1 + rand.IntN(5), true
+		case hasAny(name, "price", "amount", "cost", "total", "balance"):
+			return 100 + rand.IntN(100000), true
+		case hasAny(name, "quantity", "stock", "count", "position", "order", "sort"):
+			return rand.IntN(100), true
+		default:
+			return 1 + rand.IntN(1000), true
+		}
+
+	case schema.Float:
+		switch {
+		case name == "lat" || strings.Contains(name, "latitude"):
+			return rand.Float64()*180 - 90, true
+		case name == "lng" || name == "lon" || strings.Contains(name, "longitude"):
+			return rand.Float64()*360 - 180, true
+		case strings.Contains(name, "rating"):
+			return float64(10+rand.IntN(41)) / 10, true
+		default:
+			return float64(100+rand.IntN(100000)) / 100, true
+		}
+
+	case schema.Bool:
+		return rand.IntN(2) == 1, true
+
+	case schema.Time:
+		return fakeTime(), true
+	}
+
+	return nil, false
+}
+
+// text returns a fake string for a column based on its name
+func (f *fakeFiller) text(field *schema.Field, person fakePerson) string {
+	name := strings.ToLower(field.DBName)
+
+	switch {
+	case strings.Contains(name, "email"):
+		return person.email()
+	case hasAny(name, "username", "user_name", "login", "handle", "nickname"):
+		return person.username()
+	case hasAny(name, "first_name", "firstname", "given_name"):
+		return person.first
+	case hasAny(name, "last_name", "lastname", "surname", "family_name"):
+		return person.last
+	case hasAny(name, "full_name", "display_name", "author"):
+		return person.fullName()
+	case name == "name":
+		if personTables[f.schema.Table] {
+			return person.fullName()
+		}
+		return fakeTitle()
+	case hasAny(name, "phone", "mobile", "fax"):
+		return fakePhone()
+	case hasAny(name, "image", "avatar", "photo", "picture", "thumbnail"):
+		return fakeImageURL()
+	case hasAny(name, "url", "website", "link", "homepage"):
+		return fakeURL()
+	case strings.Contains(name, "slug"):
+		return fakeSlug()
+	case hasAny(name, "token", "code", "sku", "reference"):
+		return fakeCode(12)
+	case hasAny(name, "company", "organization", "organisation", "brand"):
+		return pick(fakeCompanies)
+	case strings.Contains(name, "city"):
+		return pick(fakeCities)
+	case strings.Contains(name, "country"):
+		return pick(fakeCountries)
+	case hasAny(name, "address", "street"):
+		return fmt.Sprintf("%d %s", 1+rand.IntN(999), pick(fakeStreets))
+	case hasAny(name, "zip", "postal", "postcode"):
+		return fmt.Sprintf("%05d", rand.IntN(100000))
+	case hasAny(name, "color", "colour"):
+		return fmt.Sprintf("#%06x", rand.IntN(0x1000000))
+	case strings.Contains(name, "currency"):
+		return pick([]string{"USD", "EUR", "GBP", "ALL", "JPY"})
+	case hasAny(name, "locale", "language", "lang"):
+		return pick([]string{"en", "sq", "de", "fr", "it", "es"})
+	case strings.Contains(name, "status"):
+		return "active"
+	case hasAny(name, "title", "subject", "headline", "label", "caption"):
+		return fakeTitle()
+	case hasAny(name, "description", "body", "content", "text", "bio", "summary", "notes", "message", "comment", "excerpt"):
+		if field.Size > 0 && field.Size <= 255 {
+			return fakeSentence()
+		}
+		return fakeParagraph()
+	default:
+		return strings.Join(fakeWordsN(2+rand.IntN(2)), " ")
+	}
+}
+
+// hasAny returns true if s contains any of the substrings
+func hasAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Word lists used to build realistic looking fake values
+var (
+	fakeFirstNames = []string{
+		"Olivia", "Liam", "Emma", "Noah", "Amelia", "Oliver", "Sophia", "Elijah", "Isabella", "Lucas",
+		"Mia", "Mateo", "Charlotte", "Leo", "Ava", "Luca", "Elena", "Arben", "Sara", "Ilir",
+		"Hana", "Marco", "Nora", "Jonas", "Lea", "David", "Yuki", "Omar", "Ines", "Tomas",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Garcia", "Miller", "Davis", "Martinez", "Lopez", "Wilson",
+		"Anderson", "Thomas", "Moore", "Martin", "Lee", "Clark", "Lewis", "Walker", "Hall", "Young",
+		"Krasniqi", "Hoxha", "Rossi", "Schmidt", "Dubois", "Novak", "Silva", "Tanaka", "Haddad", "Berg",
+	}
+	fakeWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "commodo",
+		"consequat", "duis", "aute", "irure", "reprehenderit", "voluptate", "velit", "esse", "cillum", "fugiat",
+		"nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat", "non", "proident", "sunt", "culpa",
+	}
+	fakeCompanies = []string{
+		"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli", "Vandelay", "Soylent", "Tyrell",
+		"Cyberdyne", "Aperture", "Wonka", "Gringotts", "Monarch", "Oscorp", "Pied Piper", "Dunder Mifflin",
+	}
+	fakeCities    = []string{"Tirana", "Prishtina", "Berlin", "Paris", "Rome", "Madrid", "Lisbon", "Vienna", "Oslo", "Tokyo", "Toronto", "Austin", "Denver", "Seattle", "Boston"}
+	fakeCountries = []string{"Albania", "Kosovo", "Germany", "France", "Italy", "Spain", "Portugal", "Austria", "Norway", "Japan", "Canada", "United States"}
+	fakeStreets   = []string{"Main Street", "Oak Avenue", "Maple Road", "Park Lane", "Cedar Street", "Elm Street", "Hill Road", "Lake View", "River Street", "Sunset Boulevard"}
+)
+
+// fakePerson keeps the name based values of one record consistent, so a
+// record gets e.g. "Emma Smith" with "emma.smith42@example.com"
+type fakePerson struct {
+	first  string
+	last   string
+	suffix int
+}
+
+func newFakePerson() fakePerson {
+	return fakePerson{
+		first:  pick(fakeFirstNames),
+		last:   pick(fakeLastNames),
+		suffix: rand.IntN(100000),
+	}
+}
+
+func (p fakePerson) fullName() string {
+	return p.first + " " + p.last
+}
+
+func (p fakePerson) username() string {
+	return fmt.Sprintf("%s.%s%d", strings.ToLower(p.first), strings.ToLower(p.last), p.suffix)
+}
+
+func (p fakePerson) email() string {
+	return p.username() + "@example.com"
+}
+
+// pick returns a random element of values
+func pick(values []string) string {
+	return values[rand.IntN(len(values))]
+}
+
+// fakeWordsN returns n random lorem words
+func fakeWordsN(n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = pick(fakeWords)
+	}
+	return words
+}
+
+// fakeTitle returns a short capitalized phrase
+func fakeTitle() string {
+	words := fakeWordsN(3 + rand.IntN(4))
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// fakeSentence returns a sentence of 6 to 14 words
+func fakeSentence() string {
+	return capitalize(strings.Join(fakeWordsN(6+rand.IntN(9)), " ")) + "."
+}
+
+// fakeParagraph returns 3 to 6 sentences
+func fakeParagraph() string {
+	sentences := make([]string, 3+rand.IntN(4))
+	for i := range sentences {
+		sentences[i] = fakeSentence()
+	}
+	return strings.Join(sentences, " ")
+}
+
+// fakeSlug returns a URL friendly identifier
+func fakeSlug() string {
+	return fmt.Sprintf("%s-%d", strings.Join(fakeWordsN(3), "-"), rand.IntN(10000))
+}
+
+// fakePhone returns a phone number in the reserved 555 range
+func fakePhone() string {
+	return fmt.Sprintf("+1 555 %03d %04d", rand.IntN(1000), rand.IntN(10000))
+}
+
+// fakeURL returns a website of a fake company
+func fakeURL() string {
+	company := strings.ToLower(strings.ReplaceAll(pick(fakeCompanies), " ", ""))
+	return fmt.Sprintf("https://www.%s.example.com", company)
+}
+
+// fakeImageURL returns a placeholder image URL
+func fakeImageURL() string {
+	return fmt.Sprintf("https://picsum.photos/seed/%d/640/480", rand.IntN(100000))
+}
+
+// fakeCode returns a random uppercase alphanumeric code
+func fakeCode(n int) string {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	code := make([]byte, n)
+	for i := range code {
+		code[i] = alphabet[rand.IntN(len(alphabet))]
+	}
+	return string(code)
+}
+
+// fakeTime returns a time within the last year
+func fakeTime() time.Time {
+	return time.Now().Add(-time.Duration(rand.Int64N(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+// capitalize uppercases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
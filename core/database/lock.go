@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockName identifies the migration lock across processes
+const migrationLockName = "schema_migrations"
+
+// migrationLockPoll is how often a blocked process retries a table-based lock
+const migrationLockPoll = 250 * time.Millisecond
+
+// migrationLockTTL is how long a table-based lock is held at most. A lock
+// left behind by a process that died while migrating expires after it.
+const migrationLockTTL = 10 * time.Minute
+
+// migrationLockRefresh is how often the holder of a table-based lock
+// refreshes it
+const migrationLockRefresh = time.Minute
+
+// migrationLock is a row in the fallback lock table used by drivers without
+// advisory locks (SQLite)
+type migrationLock struct {
+	Name     string    `gorm:"column:name;primaryKey;size:100"`
+	LockedAt time.Time `gorm:"column:locked_at"`
+}
+
+// TableName returns the lock table
+func (migrationLock) TableName() string {
+	return "schema_migrations_lock"
+}
+
+// withMigrationLock runs fn while holding an exclusive lock so that only one
+// process migrates the database at a time. Postgres and MySQL use advisory
+// locks bound to a dedicated connection; other drivers use a lock table.
+func withMigrationLock(ctx context.Context, db *gorm.DB, fn func() error) error {
+	switch db.Dialector.Name() {
+	case "postgres":
+		key := advisoryLockKey()
+		return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", key).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			// The session keeps the lock unless it is released, even when
+			// ctx is done
+			defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", key)
+			return fn()
+		})
+	case "mysql":
+		return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+			timeout := lockTimeoutSeconds(ctx)
+			var acquired int
+			if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLockName, timeout).Scan(&acquired).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			if acquired != 1 {
+				return errors.New("failed to acquire migration lock: timed out")
+			}
+			defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT RELEASE_LOCK(?)", migrationLockName)
+			return fn()
+		})
+	default:
+		return withTableLock(ctx, db, fn)
+	}
+}
+
+// withTableLock acquires the lock by inserting a row into the lock table.
+// The primary key guarantees only one holder; others poll until it is
+// released, or until it is older than migrationLockTTL, which is taken as
+// the holder having died. The holder refreshes the row while fn runs.
+func withTableLock(ctx context.Context, db *gorm.DB, fn func() error) error {
+	db = db.WithContext(ctx)
+	if err := db.AutoMigrate(&migrationLock{}); err != nil {
+		return fmt.Errorf("failed to create migration lock table: %w", err)
+	}
+
+	released := false
+	for {
+		err := db.Create(&migrationLock{Name: migrationLockName, LockedAt: time.Now()}).Error
+		if err == nil {
+			break
+		}
+
+		// Only a row held by another process means the lock is taken; any
+		// other failure is reported
+		var held migrationLock
+		if lookupErr := db.Where("name = ?", migrationLockName).First(&held).Error; lookupErr != nil {
+			if errors.Is(lookupErr, gorm.ErrRecordNotFound) && !released {
+				// The holder may have just released the lock
+				released = true
+				continue
+			}
+			if errors.Is(lookupErr, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			return fmt.Errorf("failed to acquire migration lock: %w", lookupErr)
+		}
+		released = false
+		if time.Since(held.LockedAt) > migrationLockTTL {
+			if err := db.Where("name = ? AND locked_at < ?", migrationLockName, time.Now().Add(-migrationLockTTL)).
+				Delete(&migrationLock{}).Error; err != nil {
+				return fmt.Errorf("failed to remove stale migration lock: %w", err)
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to acquire migration lock: %w", ctx.Err())
+		case <-time.After(migrationLockPoll):
+		}
+	}
+	defer db.WithContext(context.WithoutCancel(ctx)).Where("name = ?", migrationLockName).Delete(&migrationLock{})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		refreshTableLock(db, stop)
+	}()
+	defer wg.Wait()
+	defer close(stop)
+
+	return fn()
+}
+
+// refreshTableLock keeps the lock row from expiring until stop is closed
+func refreshTableLock(db *gorm.DB, stop <-chan struct{}) {
+	ticker := time.NewTicker(migrationLockRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// A failed refresh is retried; the lock only expires after
+			// several of them
+			db.Model(&migrationLock{}).Where("name = ?", migrationLockName).Update("locked_at", time.Now())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// advisoryLockKey derives a stable 64-bit key for pg_advisory_lock
+func advisoryLockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(migrationLockName))
+	return int64(h.Sum64())
+}
+
+// lockTimeoutSeconds returns the GET_LOCK timeout from the context deadline.
+// Without a deadline the lock is waited for indefinitely.
+func lockTimeoutSeconds(ctx context.Context) int {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return -1
+	}
+	if remaining := int(time.Until(deadline).Seconds()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single versioned schema change owned by a module.
+// Either the Go functions (Up/Down) or the raw SQL (UpSQL/DownSQL) must be set.
+type Migration struct {
+	// Version orders migrations within a module, e.g. "20250101120000"
+	Version string
+
+	// Name describes the change, e.g. "rename_posts_body_to_content"
+	Name string
+
+	// Up and Down run the migration as Go code inside a transaction
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+
+	// UpSQL and DownSQL run the migration as raw SQL inside a transaction.
+	// Statements are split on lines ending with ';'.
+	UpSQL   string
+	DownSQL string
+
+	// module is set when the migration is registered
+	module string
+}
+
+// Module returns the name of the module that registered the migration
+func (m Migration) Module() string {
+	return m.module
+}
+
+// ID returns the unique identifier of the migration across all modules
+func (m Migration) ID() string {
+	return m.module + ":" + m.Version
+}
+
+// Checksum returns a fingerprint of the migration. For SQL migrations the
+// statements are included so edits after applying are detected; Go migrations
+// can only be fingerprinted by their identity.
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s", m.module, m.Version, m.Name, m.UpSQL, m.DownSQL)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Reversible returns true if the migration can be rolled back
+func (m Migration) Reversible() bool {
+	return m.Down != nil || strings.TrimSpace(m.DownSQL) != ""
+}
+
+// validate checks the migration definition
+func (m Migration) validate() error {
+	if m.Version == "" {
+		return fmt.Errorf("migration version cannot be empty")
+	}
+	if m.Up == nil && strings.TrimSpace(m.UpSQL) == "" {
+		return fmt.Errorf("migration %s has neither Up nor UpSQL", m.Version)
+	}
+	if m.Up != nil && m.UpSQL != "" {
+		return fmt.Errorf("migration %s cannot define both Up and UpSQL", m.Version)
+	}
+	if m.Down != nil && m.DownSQL != "" {
+		return fmt.Errorf("migration %s cannot define both Down and DownSQL", m.Version)
+	}
+	return nil
+}
+
+// up applies the migration
+func (m Migration) up(tx *gorm.DB) error {
+	if m.Up != nil {
+		return m.Up(tx)
+	}
+	return execSQL(tx, m.UpSQL)
+}
+
+// down reverts the migration
+func (m Migration) down(tx *gorm.DB) error {
+	if m.Down != nil {
+		return m.Down(tx)
+	}
+	if strings.TrimSpace(m.DownSQL) == "" {
+		return fmt.Errorf("migration %s is irreversible", m.ID())
+	}
+	return execSQL(tx, m.DownSQL)
+}
+
+// execSQL executes every statement in a SQL script
+func execSQL(tx *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to execute %q: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a SQL script into statements on lines ending with ';'.
+// Comment-only lines are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// SchemaMigration records an applied migration in the schema_migrations table
+type SchemaMigration struct {
+	Id        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Module    string    `gorm:"column:module;size:100;not null;uniqueIndex:idx_schema_migrations_module_version" json:"module"`
+	Version   string    `gorm:"column:version;size:100;not null;uniqueIndex:idx_schema_migrations_module_version" json:"version"`
+	Name      string    `gorm:"column:name;size:255" json:"name"`
+	Checksum  string    `gorm:"column:checksum;size:64" json:"checksum"`
+	Batch     int       `gorm:"column:batch;not null;index" json:"batch"`
+	AppliedAt time.Time `gorm:"column:applied_at" json:"applied_at"`
+}
+
+// TableName returns the migrations tracking table
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration states reported by Status
+const (
+	MigrationApplied  = "applied"
+	MigrationPending  = "pending"
+	MigrationModified = "modified" // applied, but the checksum changed since
+	MigrationMissing  = "missing"  // applied, but no longer registered
+)
+
+// MigrationStatus describes the state of a single migration
+type MigrationStatus struct {
+	Module    string     `json:"module"`
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	Batch     int        `json:"batch,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
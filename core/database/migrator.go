@@ -0,0 +1,343 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migrator applies and rolls back versioned migrations registered by modules
+// and tracks them in the schema_migrations table
+type Migrator struct {
+	db         *gorm.DB
+	migrations map[string][]Migration
+	modules    []string // registration order
+	mu         sync.Mutex
+}
+
+// NewMigrator creates a new migrator for the given database
+func NewMigrator(db *gorm.DB) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: make(map[string][]Migration),
+	}
+}
+
+// Register adds migrations for a module. Migrations are ordered by version;
+// duplicate versions within a module are rejected.
+func (m *Migrator) Register(module string, migrations ...Migration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if module == "" {
+		return fmt.Errorf("module name cannot be empty")
+	}
+
+	existing := m.migrations[module]
+	seen := make(map[string]bool, len(existing)+len(migrations))
+	for _, mig := range existing {
+		seen[mig.Version] = true
+	}
+
+	for _, mig := range migrations {
+		if err := mig.validate(); err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+		if seen[mig.Version] {
+			return fmt.Errorf("module %s: duplicate migration version %s", module, mig.Version)
+		}
+		seen[mig.Version] = true
+		mig.module = module
+		existing = append(existing, mig)
+	}
+
+	sort.SliceStable(existing, func(i, j int) bool {
+		return compareVersions(existing[i].Version, existing[j].Version) < 0
+	})
+
+	if _, ok := m.migrations[module]; !ok {
+		m.modules = append(m.modules, module)
+	}
+	m.migrations[module] = existing
+	return nil
+}
+
+// Status returns the state of every registered and applied migration
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var statuses []MigrationStatus
+	known := make(map[string]bool)
+	for _, module := range m.modules {
+		for _, mig := range m.migrations[module] {
+			known[mig.ID()] = true
+			status := MigrationStatus{
+				Module:  module,
+				Version: mig.Version,
+				Name:    mig.Name,
+				State:   MigrationPending,
+			}
+			if row, ok := applied[mig.ID()]; ok {
+				status.State = MigrationApplied
+				if row.Checksum != mig.Checksum() {
+					status.State = MigrationModified
+				}
+				status.Batch = row.Batch
+				appliedAt := row.AppliedAt
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	// Applied migrations whose code has been removed
+	var missing []MigrationStatus
+	for id, row := range applied {
+		if known[id] {
+			continue
+		}
+		appliedAt := row.AppliedAt
+		missing = append(missing, MigrationStatus{
+			Module:    row.Module,
+			Version:   row.Version,
+			Name:      row.Name,
+			State:     MigrationMissing,
+			Batch:     row.Batch,
+			AppliedAt: &appliedAt,
+		})
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Module != missing[j].Module {
+			return missing[i].Module < missing[j].Module
+		}
+		return compareVersions(missing[i].Version, missing[j].Version) < 0
+	})
+
+	return append(statuses, missing...), nil
+}
+
+// Pending returns the migrations that Migrate would apply, without applying
+// them. If no modules are given, all registered modules are considered.
+func (m *Migrator) Pending(ctx context.Context, modules ...string) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.pending(applied, modules)
+}
+
+// Migrate applies pending migrations in registration and version order. Each
+// migration runs in its own transaction together with its tracking row, and all
+// migrations applied by one call share a batch number for rollback. If no
+// modules are given, all registered modules are migrated.
+func (m *Migrator) Migrate(ctx context.Context, modules ...string) ([]Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err := withMigrationLock(ctx, m.db, func() error {
+		// Re-read under the lock; another process may have migrated meanwhile
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		pending, err := m.pending(applied, modules)
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		batch, err := m.lastBatch(ctx)
+		if err != nil {
+			return err
+		}
+		batch++
+
+		for _, mig := range pending {
+			err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				if err := mig.up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{
+					Module:    mig.module,
+					Version:   mig.Version,
+					Name:      mig.Name,
+					Checksum:  mig.Checksum(),
+					Batch:     batch,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s (%s) failed: %w", mig.ID(), mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+
+	return done, err
+}
+
+// Rollback reverts applied migrations in reverse order. A positive steps value
+// reverts that many migrations; otherwise the last batch is reverted.
+func (m *Migrator) Rollback(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	err := withMigrationLock(ctx, m.db, func() error {
+		query := m.db.WithContext(ctx).Order("id DESC")
+		if steps > 0 {
+			query = query.Limit(steps)
+		} else {
+			batch, err := m.lastBatch(ctx)
+			if err != nil {
+				return err
+			}
+			query = query.Where("batch = ?", batch)
+		}
+
+		var rows []SchemaMigration
+		if err := query.Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to load applied migrations: %w", err)
+		}
+
+		for _, row := range rows {
+			mig, ok := m.find(row.Module, row.Version)
+			if !ok {
+				return fmt.Errorf("migration %s:%s is applied but no longer registered", row.Module, row.Version)
+			}
+
+			err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				if err := mig.down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, row.Id).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rollback of %s (%s) failed: %w", mig.ID(), mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// pending computes the unapplied migrations for the given modules. Applied
+// migrations whose checksum changed are reported as an error since the live
+// schema no longer matches the code.
+func (m *Migrator) pending(applied map[string]SchemaMigration, modules []string) ([]Migration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(modules) == 0 {
+		modules = m.modules
+	}
+
+	var pending []Migration
+	for _, module := range modules {
+		for _, mig := range m.migrations[module] {
+			row, ok := applied[mig.ID()]
+			if !ok {
+				pending = append(pending, mig)
+				continue
+			}
+			if row.Checksum != mig.Checksum() {
+				return nil, fmt.Errorf("migration %s (%s) was modified after it was applied", mig.ID(), mig.Name)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// applied returns the applied migrations keyed by migration ID
+func (m *Migrator) applied(ctx context.Context) (map[string]SchemaMigration, error) {
+	applied := make(map[string]SchemaMigration)
+	if !m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var rows []SchemaMigration
+	if err := m.db.WithContext(ctx).Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	for _, row := range rows {
+		applied[row.Module+":"+row.Version] = row
+	}
+	return applied, nil
+}
+
+// lastBatch returns the highest batch number, or 0 if nothing is applied
+func (m *Migrator) lastBatch(ctx context.Context) (int, error) {
+	var batch int
+	err := m.db.WithContext(ctx).Model(&SchemaMigration{}).
+		Select("COALESCE(MAX(batch), 0)").Scan(&batch).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration batch: %w", err)
+	}
+	return batch, nil
+}
+
+// find returns a registered migration by module and version
+func (m *Migrator) find(module, version string) (Migration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, mig := range m.migrations[module] {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+// ensureTable creates the schema_migrations table if needed
+func (m *Migrator) ensureTable() error {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// compareVersions orders numeric versions numerically ("2" < "10") and
+// falls back to string comparison otherwise
+func compareVersions(a, b string) int {
+	if isDigits(a) && isDigits(b) {
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// isDigits returns true if s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Environments a seed can be scoped to
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
+)
+
+// Seed is a named set of data owned by a module. A seed runs once per
+// database; use Environments to restrict demo or fixture data.
+type Seed struct {
+	// Name identifies the seed within its module, e.g. "default_roles"
+	Name string
+
+	// Environments restricts the seed to the given environments (EnvDevelopment,
+	// EnvTest, EnvProduction). Empty runs in every environment.
+	Environments []string
+
+	// Run inserts the data inside a transaction
+	Run func(tx *gorm.DB) error
+
+	// module is set when the seed is registered
+	module string
+}
+
+// Module returns the name of the module that registered the seed
+func (s Seed) Module() string {
+	return s.module
+}
+
+// ID returns the unique identifier of the seed across all modules
+func (s Seed) ID() string {
+	return s.module + ":" + s.Name
+}
+
+// RunsIn returns true if the seed applies to the given environment
+func (s Seed) RunsIn(env string) bool {
+	if len(s.Environments) == 0 {
+		return true
+	}
+	env = NormalizeEnvironment(env)
+	return slices.ContainsFunc(s.Environments, func(e string) bool {
+		return NormalizeEnvironment(e) == env
+	})
+}
+
+// NormalizeEnvironment maps the ENV values used by the config ("debug",
+// "dev", "release", "prod", ...) to EnvDevelopment, EnvTest or EnvProduction.
+// Unknown values are returned lowercased.
+func NormalizeEnvironment(env string) string {
+	switch env = strings.ToLower(strings.TrimSpace(env)); env {
+	case "", "debug", "dev", "development", "local":
+		return EnvDevelopment
+	case "test", "testing":
+		return EnvTest
+	case "prod", "production", "release":
+		return EnvProduction
+	default:
+		return env
+	}
+}
+
+// SchemaSeed records a seed that has run in the schema_seeds table
+type SchemaSeed struct {
+	Id          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Module      string    `gorm:"column:module;size:100;not null;uniqueIndex:idx_schema_seeds_module_name" json:"module"`
+	Name        string    `gorm:"column:name;size:255;not null;uniqueIndex:idx_schema_seeds_module_name" json:"name"`
+	Environment string    `gorm:"column:environment;size:50" json:"environment"`
+	RanAt       time.Time `gorm:"column:ran_at" json:"ran_at"`
+}
+
+// TableName returns the seeds tracking table
+func (SchemaSeed) TableName() string {
+	return "schema_seeds"
+}
+
+// Seed states reported by Status
+const (
+	SeedRan     = "ran"
+	SeedPending = "pending"
+	SeedSkipped = "skipped" // not scoped to the current environment
+)
+
+// SeedStatus describes the state of a single seed
+type SeedStatus struct {
+	Module       string     `json:"module"`
+	Name         string     `json:"name"`
+	Environments []string   `json:"environments,omitempty"`
+	State        string     `json:"state"`
+	RanAt        *time.Time `json:"ran_at,omitempty"`
+}
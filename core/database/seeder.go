@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SeedRunner runs the seeds registered by modules for one environment and
+// tracks them in the schema_seeds table so each seed runs only once
+type SeedRunner struct {
+	db      *gorm.DB
+	env     string
+	seeds   map[string][]Seed
+	modules []string // registration order
+	mu      sync.Mutex
+}
+
+// NewSeedRunner creates a seed runner for the given database and environment
+func NewSeedRunner(db *gorm.DB, env string) *SeedRunner {
+	return &SeedRunner{
+		db:    db,
+		env:   NormalizeEnvironment(env),
+		seeds: make(map[string][]Seed),
+	}
+}
+
+// Environment returns the normalized environment seeds are run for
+func (r *SeedRunner) Environment() string {
+	return r.env
+}
+
+// Register adds seeds for a module. Seeds run in registration order;
+// duplicate names within a module are rejected.
+func (r *SeedRunner) Register(module string, seeds ...Seed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if module == "" {
+		return fmt.Errorf("module name cannot be empty")
+	}
+
+	existing := r.seeds[module]
+	seen := make(map[string]bool, len(existing)+len(seeds))
+	for _, seed := range existing {
+		seen[seed.Name] = true
+	}
+
+	for _, seed := range seeds {
+		if seed.Name == "" {
+			return fmt.Errorf("module %s: seed name cannot be empty", module)
+		}
+		if seed.Run == nil {
+			return fmt.Errorf("module %s: seed %s has no Run function", module, seed.Name)
+		}
+		if seen[seed.Name] {
+			return fmt.Errorf("module %s: duplicate seed %s", module, seed.Name)
+		}
+		seen[seed.Name] = true
+		seed.module = module
+		existing = append(existing, seed)
+	}
+
+	if _, ok := r.seeds[module]; !ok {
+		r.modules = append(r.modules, module)
+	}
+	r.seeds[module] = existing
+	return nil
+}
+
+// Status returns the state of every registered seed
+func (r *SeedRunner) Status(ctx context.Context) ([]SeedStatus, error) {
+	ran, err := r.ran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var statuses []SeedStatus
+	for _, module := range r.modules {
+		for _, seed := range r.seeds[module] {
+			status := SeedStatus{
+				Module:       module,
+				Name:         seed.Name,
+				Environments: seed.Environments,
+				State:        SeedPending,
+			}
+			if row, ok := ran[seed.ID()]; ok {
+				status.State = SeedRan
+				ranAt := row.RanAt
+				status.RanAt = &ranAt
+			} else if !seed.RunsIn(r.env) {
+				status.State = SeedSkipped
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// Run runs the seeds of the current environment that have not run yet, in
+// registration order. Each seed runs in its own transaction together with its
+// tracking row. With force, seeds that already ran are run again. If no
+// modules are given, all registered modules are seeded.
+func (r *SeedRunner) Run(ctx context.Context, force bool, modules ...string) ([]Seed, error) {
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var done []Seed
+	err := withMigrationLock(ctx, r.db, func() error {
+		// Re-read under the lock; another process may have seeded meanwhile
+		ran, err := r.ran(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, seed := range r.pending(ran, force, modules) {
+			err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				if err := seed.Run(tx); err != nil {
+					return err
+				}
+				if row, ok := ran[seed.ID()]; ok {
+					return tx.Model(&SchemaSeed{}).Where("id = ?", row.Id).Updates(map[string]any{
+						"environment": r.env,
+						"ran_at":      time.Now(),
+					}).Error
+				}
+				return tx.Create(&SchemaSeed{
+					Module:      seed.module,
+					Name:        seed.Name,
+					Environment: r.env,
+					RanAt:       time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("seed %s failed: %w", seed.ID(), err)
+			}
+			done = append(done, seed)
+		}
+		return nil
+	})
+
+	return done, err
+}
+
+// pending computes the seeds to run for the given modules
+func (r *SeedRunner) pending(ran map[string]SchemaSeed, force bool, modules []string) []Seed {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(modules) == 0 {
+		modules = r.modules
+	}
+
+	var pending []Seed
+	for _, module := range modules {
+		for _, seed := range r.seeds[module] {
+			if !seed.RunsIn(r.env) {
+				continue
+			}
+			if _, ok := ran[seed.ID()]; ok && !force {
+				continue
+			}
+			pending = append(pending, seed)
+		}
+	}
+	return pending
+}
+
+// ran returns the seeds that have run keyed by seed ID
+func (r *SeedRunner) ran(ctx context.Context) (map[string]SchemaSeed, error) {
+	ran := make(map[string]SchemaSeed)
+	if !r.db.Migrator().HasTable(&SchemaSeed{}) {
+		return ran, nil
+	}
+
+	var rows []SchemaSeed
+	if err := r.db.WithContext(ctx).Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load seeds: %w", err)
+	}
+	for _, row := range rows {
+		ran[row.Module+":"+row.Name] = row
+	}
+	return ran, nil
+}
+
+// ensureTable creates the schema_seeds table if needed
+func (r *SeedRunner) ensureTable() error {
+	if err := r.db.AutoMigrate(&SchemaSeed{}); err != nil {
+		return fmt.Errorf("failed to create schema_seeds table: %w", err)
+	}
+	return nil
+}
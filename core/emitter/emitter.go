@@ -9,6 +9,8 @@ import (
 
 type Emitter struct {
 	listeners map[string][]func(any)
+	ids       map[string][]uint64 // listener ids, parallel to listeners
+	nextID    uint64
 	mutex     sync.RWMutex
 }
 
@@ -21,7 +23,55 @@ func New() *Emitter {
 func (e *Emitter) On(event string, listener func(any)) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
+	e.add(event, listener)
+}
+
+// Subscribe adds a listener like On and returns a function that removes it.
+// Listeners must not unsubscribe from within Emit.
+func (e *Emitter) Subscribe(event string, listener func(any)) (unsubscribe func()) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	id := e.add(event, listener)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mutex.Lock()
+			defer e.mutex.Unlock()
+			e.remove(event, id)
+		})
+	}
+}
+
+// add registers a listener and returns its id; the caller holds the lock
+func (e *Emitter) add(event string, listener func(any)) uint64 {
+	if e.listeners == nil {
+		e.listeners = make(map[string][]func(any))
+	}
+	if e.ids == nil {
+		e.ids = make(map[string][]uint64)
+	}
+	e.nextID++
 	e.listeners[event] = append(e.listeners[event], listener)
+	e.ids[event] = append(e.ids[event], e.nextID)
+	return e.nextID
+}
+
+// remove unregisters a listener by id; the caller holds the lock
+func (e *Emitter) remove(event string, id uint64) {
+	for i, listenerID := range e.ids[event] {
+		if listenerID != id {
+			continue
+		}
+		// Copy so that snapshots taken by EmitAsync are not modified
+		e.listeners[event] = append(append([]func(any){}, e.listeners[event][:i]...), e.listeners[event][i+1:]...)
+		e.ids[event] = append(append([]uint64{}, e.ids[event][:i]...), e.ids[event][i+1:]...)
+		if len(e.ids[event]) == 0 {
+			delete(e.listeners, event)
+			delete(e.ids, event)
+		}
+		return
+	}
 }
 
 func (e *Emitter) Emit(event string, data any) {
@@ -49,6 +99,7 @@ func (e *Emitter) Clear() {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	e.listeners = make(map[string][]func(any))
+	e.ids = make(map[string][]uint64)
 }
 
 // EmitAsync emits an event asynchronously without blocking
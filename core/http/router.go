@@ -166,25 +166,19 @@ type Middleware func(next Handler) Handler
 // ErrorHandler handles errors returned by handlers
 type ErrorHandler func(ctx Context, err error)
 
-// Server defines the HTTP server interface
+// Server defines the HTTP server lifecycle interface
 type Server interface {
-	// Router returns the router
-	Router() Router
+	// Handler returns the root handler served by the server
+	Handler() http.Handler
 
-	// Start starts the server
+	// Start starts the server and blocks until it is shut down
 	Start(addr string) error
 
-	// StartTLS starts the server with TLS
+	// StartTLS starts the server with TLS and blocks until it is shut down
 	StartTLS(addr, certFile, keyFile string) error
 
-	// Shutdown gracefully shuts down the server
+	// Shutdown gracefully shuts down the server, draining in-flight requests
 	Shutdown(ctx context.Context) error
-
-	// Use adds global middleware
-	Use(middlewares ...Middleware)
-
-	// SetErrorHandler sets the error handler
-	SetErrorHandler(handler ErrorHandler)
 }
 
 // ServerConfig contains server configuration
@@ -198,6 +192,12 @@ type ServerConfig struct {
 	// IdleTimeout is the maximum amount of time to wait for the next request
 	IdleTimeout time.Duration
 
+	// ReadHeaderTimeout is the amount of time allowed to read request headers
+	ReadHeaderTimeout time.Duration
+
+	// ShutdownTimeout is the maximum duration to wait for in-flight requests to drain
+	ShutdownTimeout time.Duration
+
 	// MaxHeaderBytes controls the maximum number of bytes the server will read
 	MaxHeaderBytes int
 
@@ -208,5 +208,17 @@ type ServerConfig struct {
 	ErrorHandler ErrorHandler
 }
 
+// DefaultServerConfig returns a server configuration with production-safe timeouts
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+	}
+}
+
 // NewServer creates a new HTTP server with the given configuration
 type NewServer func(config *ServerConfig) Server
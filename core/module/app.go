@@ -36,7 +36,10 @@ func (ao *AppOrchestrator) InitializeAppModules(deps Dependencies) ([]Module, er
 	}
 
 	// Initialize them using the generic initializer
-	initializedModules := ao.initializer.Initialize(modules, deps)
+	initializedModules, err := ao.initializer.Initialize(modules, deps)
+	if err != nil {
+		return nil, err
+	}
 
 	deps.Logger.Info(fmt.Sprintf("✅ App modules initialization complete (%d modules)", len(initializedModules)))
 	return initializedModules, nil
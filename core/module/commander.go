@@ -0,0 +1,557 @@
+package module
+
+import (
+	"base/core/database"
+	"base/core/router"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gorm.io/gorm"
+)
+
+// Commander is implemented by modules that contribute management commands to
+// the application binary. Commands are named <module>:<action>, e.g.
+// "users:create". They run as built after every module is initialized,
+// without the HTTP listener, so they can use the same dependencies (DB,
+// logger, storage, email) the module was created with.
+//
+// Commands is also called on a zero module to describe the commands in help
+// before any dependency is set up (see DescribeCommands), so it must only use
+// the module's fields inside the commands' run functions.
+type Commander interface {
+	Commands() []*cobra.Command
+}
+
+// namedCommander is a Commander and the name of its module
+type namedCommander struct {
+	name      string
+	commander Commander
+}
+
+// Commands returns the commands contributed by initialized modules, in
+// initialization order. A command whose name is already taken or is not of
+// the form <module>:<action> is skipped.
+func (mi *Initializer) Commands() []*cobra.Command {
+	var commanders []namedCommander
+	for _, entry := range mi.modules {
+		if commander, ok := entry.module.(Commander); ok {
+			commanders = append(commanders, namedCommander{name: entry.name, commander: commander})
+		}
+	}
+
+	return collectCommands(commanders, func(msg string) {
+		mi.logger.Warn(msg)
+	})
+}
+
+// DescribeCommands returns the commands of zero modules, by module name, for
+// listing them before any dependency is set up. They must not run as is:
+// LazyCommand makes them run the commands of the initialized modules.
+func DescribeCommands(modules map[string]Commander) []*cobra.Command {
+	commanders := make([]namedCommander, 0, len(modules))
+	for _, name := range slices.Sorted(maps.Keys(modules)) {
+		commanders = append(commanders, namedCommander{name: name, commander: modules[name]})
+	}
+
+	// Skipped commands are reported by Commands once the modules run
+	return collectCommands(commanders, func(string) {})
+}
+
+// collectCommands returns the well-formed commands of the modules, the first
+// module registering a name keeping it
+func collectCommands(commanders []namedCommander, warn func(msg string)) []*cobra.Command {
+	var commands []*cobra.Command
+	owners := make(map[string]string)
+
+	for _, entry := range commanders {
+		for _, cmd := range entry.commander.Commands() {
+			if !IsModuleCommand(cmd.Name()) {
+				warn(fmt.Sprintf("Skipping command %s of module %s: module commands are named <module>:<action>", cmd.Name(), entry.name))
+				continue
+			}
+			if owner, exists := owners[cmd.Name()]; exists {
+				warn(fmt.Sprintf("Skipping command %s of module %s: already registered by %s", cmd.Name(), entry.name, owner))
+				continue
+			}
+			owners[cmd.Name()] = entry.name
+			commands = append(commands, cmd)
+		}
+	}
+
+	return commands
+}
+
+// LazyCommand turns a described module command into one that runs the
+// command of the same name among those resolve returns, e.g. the commands of
+// the initialized modules. resolve is only called once the command runs, so
+// the dependencies can be set up in a PersistentPreRunE of the root command.
+// Flags set on the described command are copied to the resolved one.
+func LazyCommand(described *cobra.Command, resolve func() []*cobra.Command) *cobra.Command {
+	return lazyCommand(described, func() (*cobra.Command, error) {
+		return findCommand(resolve(), described.Name())
+	})
+}
+
+// lazyCommand makes a described command and its subcommands run the
+// commands resolve returns
+func lazyCommand(described *cobra.Command, resolve func() (*cobra.Command, error)) *cobra.Command {
+	for _, sub := range described.Commands() {
+		lazyCommand(sub, func() (*cobra.Command, error) {
+			parent, err := resolve()
+			if err != nil {
+				return nil, err
+			}
+			return findCommand(parent.Commands(), sub.Name())
+		})
+	}
+	if !described.Runnable() {
+		return described
+	}
+
+	described.PreRun, described.PreRunE = nil, nil
+	described.PostRun, described.PostRunE = nil, nil
+	described.Run = nil
+	described.RunE = func(cmd *cobra.Command, args []string) error {
+		target, err := resolve()
+		if err != nil {
+			return err
+		}
+		if err := copyFlags(cmd, target); err != nil {
+			return err
+		}
+		target.SetContext(cmd.Context())
+		target.SetIn(cmd.InOrStdin())
+		target.SetOut(cmd.OutOrStdout())
+		target.SetErr(cmd.ErrOrStderr())
+		return runHooks(target, args)
+	}
+	return described
+}
+
+// findCommand returns the command with a name
+func findCommand(commands []*cobra.Command, name string) (*cobra.Command, error) {
+	for _, cmd := range commands {
+		if cmd.Name() == name {
+			return cmd, nil
+		}
+	}
+	return nil, fmt.Errorf("command %s is not available", name)
+}
+
+// copyFlags sets the flags changed on the command line on target
+func copyFlags(from, target *cobra.Command) error {
+	// Merges the persistent flags target inherits into target.Flags()
+	target.InheritedFlags()
+
+	var err error
+	from.Flags().Visit(func(flag *pflag.Flag) {
+		to := target.Flags().Lookup(flag.Name)
+		if err != nil || to == nil {
+			return
+		}
+		if values, ok := flag.Value.(pflag.SliceValue); ok {
+			if toValues, ok := to.Value.(pflag.SliceValue); ok {
+				err = toValues.Replace(values.GetSlice())
+				to.Changed = true
+				return
+			}
+		}
+		err = to.Value.Set(flag.Value.String())
+		to.Changed = true
+	})
+	return err
+}
+
+// runHooks runs a command's run functions the way cobra does, without
+// parsing the command line again
+func runHooks(cmd *cobra.Command, args []string) error {
+	switch {
+	case cmd.PreRunE != nil:
+		if err := cmd.PreRunE(cmd, args); err != nil {
+			return err
+		}
+	case cmd.PreRun != nil:
+		cmd.PreRun(cmd, args)
+	}
+
+	switch {
+	case cmd.RunE != nil:
+		if err := cmd.RunE(cmd, args); err != nil {
+			return err
+		}
+	case cmd.Run != nil:
+		cmd.Run(cmd, args)
+	}
+
+	switch {
+	case cmd.PostRunE != nil:
+		return cmd.PostRunE(cmd, args)
+	case cmd.PostRun != nil:
+		cmd.PostRun(cmd, args)
+	}
+	return nil
+}
+
+// IsModuleCommand reports whether a command name has the form of a module
+// command, <module>:<action>
+func IsModuleCommand(name string) bool {
+	module, action, ok := strings.Cut(name, ":")
+	return ok && module != "" && action != ""
+}
+
+// MigrateCommand returns the "migrate" command for the modules initialized by
+// the initializer. The initializer should have auto migration disabled so the
+// command controls when schema changes are applied.
+func MigrateCommand(mi *Initializer) *cobra.Command {
+	var seed bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending migrations (auto migrations and versioned migrations)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applied, err := mi.MigrateAll(cmd.Context())
+			for _, mig := range applied {
+				fmt.Fprintf(cmd.OutOrStdout(), "applied  %s  %s\n", mig.ID(), mig.Name)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d migration(s) applied\n", len(applied))
+
+			if !seed {
+				return nil
+			}
+			return runSeeds(cmd, mi, false, nil)
+		},
+	}
+
+	cmd.Flags().BoolVar(&seed, "seed", false, "Run pending seeds after migrating")
+
+	cmd.AddCommand(
+		migrateStatusCommand(mi),
+		migrateRollbackCommand(mi),
+		migrateDriftCommand(mi),
+	)
+
+	return cmd
+}
+
+// migrateStatusCommand lists every migration and its state
+func migrateStatusCommand(mi *Initializer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of every versioned migration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := mi.Migrator().Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(statuses) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No migrations registered")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MODULE\tVERSION\tNAME\tSTATE\tBATCH\tAPPLIED AT")
+			for _, s := range statuses {
+				batch, appliedAt := "-", "-"
+				if s.AppliedAt != nil {
+					batch = fmt.Sprint(s.Batch)
+					appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Module, s.Version, s.Name, s.State, batch, appliedAt)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// migrateRollbackCommand reverts the last batch or a number of migrations
+func migrateRollbackCommand(mi *Initializer) *cobra.Command {
+	var steps int
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Revert the last batch of versioned migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reverted, err := mi.Migrator().Rollback(cmd.Context(), steps)
+			for _, mig := range reverted {
+				fmt.Fprintf(cmd.OutOrStdout(), "reverted  %s  %s\n", mig.ID(), mig.Name)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d migration(s) reverted\n", len(reverted))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&steps, "steps", 0, "Number of migrations to revert (default: the last batch)")
+
+	return cmd
+}
+
+// migrateDriftCommand compares module models with the live schema
+func migrateDriftCommand(mi *Initializer) *cobra.Command {
+	var failOnDrift bool
+
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Dry-run comparison of module models with the live database schema",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := mi.Migrator().Drift(cmd.Context(), mi.Models()...)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !report.HasDrift() {
+				fmt.Fprintf(out, "No drift detected (%s)\n", report.Driver)
+				return nil
+			}
+
+			for _, table := range report.Tables {
+				fmt.Fprintf(out, "%s (%s)\n", table.Table, table.Model)
+				if table.MissingTable {
+					fmt.Fprintln(out, "  missing table")
+				}
+				for _, column := range table.MissingColumns {
+					fmt.Fprintf(out, "  missing column  %s\n", column)
+				}
+				for _, column := range table.ExtraColumns {
+					fmt.Fprintf(out, "  extra column    %s\n", column)
+				}
+				for _, index := range table.MissingIndexes {
+					fmt.Fprintf(out, "  missing index   %s\n", index)
+				}
+			}
+
+			if failOnDrift {
+				return fmt.Errorf("schema drift detected in %d table(s)", len(report.Tables))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&failOnDrift, "fail", false, "Exit with an error when drift is detected (for CI)")
+
+	return cmd
+}
+
+// SeedCommand returns the "seed" command for the modules initialized by the
+// initializer. The initializer should have auto seeding disabled so the
+// command reports the seeds it ran.
+func SeedCommand(mi *Initializer) *cobra.Command {
+	var force bool
+	var modules []string
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Run the seeds of the current environment that have not run yet",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSeeds(cmd, mi, force, modules)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Run seeds again even if they already ran")
+	cmd.Flags().StringSliceVar(&modules, "module", nil, "Only seed the given modules")
+
+	cmd.AddCommand(
+		seedStatusCommand(mi),
+		seedFakeCommand(mi),
+	)
+
+	return cmd
+}
+
+// runSeeds runs seeds and prints the ones that ran
+func runSeeds(cmd *cobra.Command, mi *Initializer, force bool, modules []string) error {
+	ran, err := mi.SeedAll(cmd.Context(), force, modules...)
+	for _, seed := range ran {
+		fmt.Fprintf(cmd.OutOrStdout(), "seeded  %s\n", seed.ID())
+	}
+	if err != nil {
+		return err
+	}
+
+	env := ""
+	if mi.Seeds() != nil {
+		env = mi.Seeds().Environment()
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d seed(s) ran (%s)\n", len(ran), env)
+	return nil
+}
+
+// seedStatusCommand lists every seed and its state
+func seedStatusCommand(mi *Initializer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of every seed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if mi.Seeds() == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "No seeds registered")
+				return nil
+			}
+			statuses, err := mi.Seeds().Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if len(statuses) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No seeds registered")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MODULE\tNAME\tENVIRONMENTS\tSTATE\tRAN AT")
+			for _, s := range statuses {
+				envs, ranAt := "all", "-"
+				if len(s.Environments) > 0 {
+					envs = strings.Join(s.Environments, ",")
+				}
+				if s.RanAt != nil {
+					ranAt = s.RanAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Module, s.Name, envs, s.State, ranAt)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// seedFakeCommand inserts fake records of a module model
+func seedFakeCommand(mi *Initializer) *cobra.Command {
+	var count int
+
+	cmd := &cobra.Command{
+		Use:     "fake <model>",
+		Short:   "Insert fake records of a model, by table or type name",
+		Example: "  base seed fake users --count 25",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if mi.DB() == nil {
+				return fmt.Errorf("no database configured")
+			}
+			if mi.Seeds() != nil && mi.Seeds().Environment() == database.EnvProduction {
+				return fmt.Errorf("refusing to insert fake records in production")
+			}
+
+			model, table, err := findModel(mi, args[0])
+			if err != nil {
+				return err
+			}
+
+			created, err := database.CreateFakes(mi.DB().WithContext(cmd.Context()), model, count)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ %d fake %s record(s) created\n", len(created), table)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&count, "count", "n", 10, "Number of records to create")
+
+	return cmd
+}
+
+// findModel returns the model of an initialized module whose table or type
+// name matches name (case-insensitive)
+func findModel(mi *Initializer, name string) (any, string, error) {
+	var available []string
+	for _, model := range mi.Models() {
+		stmt := &gorm.Statement{DB: mi.DB()}
+		if err := stmt.Parse(model); err != nil {
+			continue
+		}
+		sch := stmt.Schema
+		if strings.EqualFold(sch.Table, name) || strings.EqualFold(reflect.Indirect(reflect.ValueOf(model)).Type().Name(), name) {
+			return model, sch.Table, nil
+		}
+		available = append(available, sch.Table)
+	}
+	return nil, "", fmt.Errorf("model %s not found; available: %s", name, strings.Join(available, ", "))
+}
+
+// RoutesCommand returns the "routes" command listing the routes registered on
+// the router by the application and its modules
+func RoutesCommand(r *router.Router) *cobra.Command {
+	var (
+		asJSON bool
+		module string
+		method string
+		host   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "List the registered routes with their names, modules and middleware",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var routes []router.Route
+			for _, route := range r.Routes() {
+				if module != "" && route.Module != module {
+					continue
+				}
+				if method != "" && !strings.EqualFold(route.Method, method) {
+					continue
+				}
+				if host != "" && !strings.EqualFold(route.Host, host) {
+					continue
+				}
+				routes = append(routes, route)
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(routes)
+			}
+
+			if len(routes) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No routes registered")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tVERSION\tNAME\tMODULE\tMIDDLEWARE")
+			for _, route := range routes {
+				name, version, owner, middleware := "-", "-", "-", "-"
+				if route.Name != "" {
+					name = route.Name
+				}
+				if route.Version != "" {
+					version = route.Version
+				}
+				if route.Module != "" {
+					owner = route.Module
+				}
+				if len(route.Middleware) > 0 {
+					middleware = strings.Join(route.Middleware, ",")
+				}
+				// Routes of virtual hosts are listed with their host
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Host+route.Path, version, name, owner, middleware)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the routes as JSON")
+	cmd.Flags().StringVar(&module, "module", "", "Only list the routes of a module")
+	cmd.Flags().StringVar(&method, "method", "", "Only list the routes of an HTTP method")
+	cmd.Flags().StringVar(&host, "host", "", "Only list the routes of a virtual host pattern")
+
+	return cmd
+}
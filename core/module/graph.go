@@ -0,0 +1,96 @@
+package module
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dependent is implemented by modules that must be initialized after other
+// modules, e.g. because their models reference another module's tables
+type Dependent interface {
+	DependsOn() []string
+}
+
+// dependenciesOf returns the declared dependencies of a module
+func dependenciesOf(mod Module) []string {
+	if dependent, ok := mod.(Dependent); ok {
+		return dependent.DependsOn()
+	}
+	return nil
+}
+
+// SortModules returns module names in dependency order. Modules without an
+// ordering constraint between them are sorted alphabetically so the result is
+// deterministic. Dependencies may be satisfied by another module in the map or
+// by an already initialized module reported by the available callback.
+// It returns an error for missing dependencies and dependency cycles.
+func SortModules(modules map[string]Module, available func(name string) bool) ([]string, error) {
+	inDegree := make(map[string]int, len(modules))
+	dependents := make(map[string][]string, len(modules))
+
+	for name := range modules {
+		inDegree[name] = 0
+	}
+
+	// Build the graph and validate that every dependency exists
+	var missing []string
+	for name, mod := range modules {
+		for _, dep := range dependenciesOf(mod) {
+			if dep == name {
+				return nil, fmt.Errorf("error: Module %s depends on itself", name)
+			}
+			if _, ok := modules[dep]; ok {
+				inDegree[name]++
+				dependents[dep] = append(dependents[dep], name)
+				continue
+			}
+			if available != nil && available(dep) {
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("%s -> %s", name, dep))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("error: Missing module dependencies: %s", strings.Join(missing, ", "))
+	}
+
+	// Kahn's algorithm with an alphabetically sorted ready queue
+	var ready []string
+	for name, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, name)
+		}
+	}
+	sort.Strings(ready)
+
+	order := make([]string, 0, len(modules))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, dependent := range dependents[name] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		sort.Strings(ready)
+	}
+
+	// Any module left with unresolved dependencies is part of a cycle
+	if len(order) < len(modules) {
+		var cycle []string
+		for name, degree := range inDegree {
+			if degree > 0 {
+				cycle = append(cycle, name)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("error: Module dependency cycle detected between: %s", strings.Join(cycle, ", "))
+	}
+
+	return order, nil
+}
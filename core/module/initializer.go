@@ -1,7 +1,13 @@
 package module
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
 	"base/core/config"
+	"base/core/database"
 	"base/core/email"
 	"base/core/emitter"
 	"base/core/logger"
@@ -20,63 +26,364 @@ type Dependencies struct {
 	Storage     *storage.ActiveStorage
 	EmailSender email.Sender
 	Config      *config.Config
+	Services    *Services
 }
 
 // Initializer handles module initialization logic
 type Initializer struct {
-	logger logger.Logger
+	logger           logger.Logger
+	db               *gorm.DB
+	lifecycle        *Lifecycle
+	migrator         *database.Migrator
+	seeds            *database.SeedRunner
+	services         *Services
+	modules          []lifecycleEntry
+	manualMigrations bool
+	manualSeeds      bool
+	initialized      map[string]bool
+	failed           map[string]bool
 }
 
 // NewInitializer creates a new module initializer
 func NewInitializer(logger logger.Logger) *Initializer {
 	return &Initializer{
-		logger: logger,
+		logger:      logger,
+		lifecycle:   NewLifecycle(logger, DefaultLifecycleTimeout),
+		services:    NewServices(),
+		initialized: make(map[string]bool),
+		failed:      make(map[string]bool),
+	}
+}
+
+// Lifecycle returns the lifecycle that tracks every module initialized by this
+// initializer, used to start and stop them once routing is set up
+func (mi *Initializer) Lifecycle() *Lifecycle {
+	return mi.lifecycle
+}
+
+// Services returns the service container modules provide and resolve
+// services through
+func (mi *Initializer) Services() *Services {
+	return mi.services
+}
+
+// Migrator returns the migrator holding the versioned migrations of every
+// module initialized so far. It is nil until Initialize has been called.
+func (mi *Initializer) Migrator() *database.Migrator {
+	return mi.migrator
+}
+
+// DB returns the database modules were initialized with
+func (mi *Initializer) DB() *gorm.DB {
+	return mi.db
+}
+
+// Seeds returns the seed runner holding the seeds of every module initialized
+// so far. It is nil until Initialize has been called.
+func (mi *Initializer) Seeds() *database.SeedRunner {
+	return mi.seeds
+}
+
+// Models returns the models of every module initialized so far, e.g. to
+// compare them against the live schema with Migrator().Drift
+func (mi *Initializer) Models() []any {
+	var models []any
+	for _, entry := range mi.modules {
+		models = append(models, entry.module.GetModels()...)
 	}
+	return models
 }
 
-// Initialize initializes a map of modules with dependencies
-func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies) []Module {
+// SetAutoMigrate controls whether modules are migrated while they are
+// initialized (the default). When disabled, Migrate and versioned migrations
+// only run through MigrateAll, e.g. from the migrate command.
+func (mi *Initializer) SetAutoMigrate(enabled bool) {
+	mi.manualMigrations = !enabled
+}
+
+// MigrateAll runs Migrate on every initialized module in initialization order
+// and then applies all pending versioned migrations
+func (mi *Initializer) MigrateAll(ctx context.Context) ([]database.Migration, error) {
+	for _, entry := range mi.modules {
+		if err := entry.module.Migrate(); err != nil {
+			return nil, fmt.Errorf("migrate %s: %w", entry.name, err)
+		}
+	}
+	if mi.migrator == nil {
+		return nil, nil
+	}
+	return mi.migrator.Migrate(ctx)
+}
+
+// SetAutoSeed controls whether module seeds run while modules are initialized
+// (the default). Seeds never run automatically when auto migration is disabled.
+func (mi *Initializer) SetAutoSeed(enabled bool) {
+	mi.manualSeeds = !enabled
+}
+
+// SeedAll runs the seeds of the given modules, or of every initialized module,
+// in initialization order. With force, seeds that already ran are run again.
+func (mi *Initializer) SeedAll(ctx context.Context, force bool, modules ...string) ([]database.Seed, error) {
+	if mi.seeds == nil {
+		return nil, nil
+	}
+	return mi.seeds.Run(ctx, force, modules...)
+}
+
+// Initialize initializes a map of modules in dependency order. Modules
+// initialized by earlier calls on the same initializer satisfy dependencies,
+// so core modules can be initialized before app modules that depend on them.
+// It returns an error without initializing anything if a dependency is
+// missing or the dependency graph has a cycle. A module that fails is
+// skipped along with every module depending on it.
+func (mi *Initializer) Initialize(modules map[string]Module, deps Dependencies) ([]Module, error) {
+	order, err := SortModules(modules, func(name string) bool {
+		return mi.initialized[name] || mi.failed[name]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if mi.db == nil {
+		mi.db = deps.DB
+	}
+	if mi.migrator == nil && deps.DB != nil {
+		mi.migrator = database.NewMigrator(deps.DB)
+	}
+	if mi.seeds == nil && deps.DB != nil {
+		env := ""
+		if deps.Config != nil {
+			env = deps.Config.Env
+		}
+		mi.seeds = database.NewSeedRunner(deps.DB, env)
+	}
+
+	// Register every module's services first so that services are available
+	// regardless of initialization order
+	for _, name := range order {
+		provider, ok := modules[name].(ServiceProvider)
+		if !ok {
+			continue
+		}
+		if err := provider.ProvideServices(mi.services.ForModule(name)); err != nil {
+			mi.logger.Error("Failed to provide module services",
+				logger.String("module", name),
+				logger.String("error", err.Error()))
+			mi.failed[name] = true
+		}
+	}
+
 	var initializedModules []Module
 
-	for name, mod := range modules {
-		mi.logger.Info("Initializing module", logger.String("module", name))
+	for _, name := range order {
+		mod := modules[name]
+		if mi.failed[name] {
+			continue
+		}
 
-		// Register module
-		if err := RegisterModule(name, mod); err != nil {
-			mi.logger.Error("Failed to register module",
+		// Skip modules whose dependencies failed
+		if failedDep := mi.failedDependency(mod); failedDep != "" {
+			mi.logger.Error("Skipping module because a dependency failed",
+				logger.String("module", name),
+				logger.String("dependency", failedDep))
+			mi.failed[name] = true
+			continue
+		}
+
+		if err := mi.initializeModule(name, mod, deps); err != nil {
+			mi.logger.Error("Failed to initialize module",
 				logger.String("module", name),
 				logger.String("error", err.Error()))
+			mi.failed[name] = true
 			continue
 		}
 
-		// Initialize
-		if initModule, ok := mod.(interface{ Init() error }); ok {
-			if err := initModule.Init(); err != nil {
-				mi.logger.Error("Failed to initialize module",
-					logger.String("module", name),
-					logger.String("error", err.Error()))
-				continue
-			}
+		initializedModules = append(initializedModules, mod)
+		mi.modules = append(mi.modules, lifecycleEntry{name: name, module: mod})
+		mi.initialized[name] = true
+		mi.lifecycle.Add(name, mod)
+		mi.logger.Info("Module initialized successfully", logger.String("module", name))
+	}
+
+	return initializedModules, nil
+}
+
+// initializeModule registers, initializes, migrates and routes a single module
+func (mi *Initializer) initializeModule(name string, mod Module, deps Dependencies) error {
+	mi.logger.Info("Initializing module", logger.String("module", name))
+
+	// Register module
+	if err := RegisterModule(name, mod); err != nil {
+		return fmt.Errorf("register: %w", err)
+	}
+
+	// Resolve services of other modules
+	if consumer, ok := mod.(ServiceConsumer); ok {
+		if err := consumer.ResolveServices(mi.services.ForModule(name)); err != nil {
+			return fmt.Errorf("services: %w", err)
+		}
+	}
+
+	// Initialize
+	if err := mod.Init(); err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+
+	// Migrate
+	if !mi.manualMigrations {
+		if err := mod.Migrate(); err != nil {
+			return fmt.Errorf("migrate: %w", err)
 		}
+	}
+
+	// Versioned migrations
+	if err := mi.runMigrations(name, mod); err != nil {
+		return fmt.Errorf("migrations: %w", err)
+	}
+
+	// Seeds
+	if err := mi.runSeeds(name, mod); err != nil {
+		return fmt.Errorf("seeds: %w", err)
+	}
 
-		// Migrate
-		if migrator, ok := mod.(interface{ Migrate() error }); ok {
-			if err := migrator.Migrate(); err != nil {
-				mi.logger.Error("Failed to migrate module",
-					logger.String("module", name),
-					logger.String("error", err.Error()))
+	// Setup routes
+	routes := deps.Router.WithModule(name)
+	mod.Routes(routes)
+
+	// Middleware overrides
+	if configurable, ok := mod.(ConfigurableModule); ok && deps.Config != nil {
+		mi.applyMiddlewareOverrides(name, configurable, routes, &deps.Config.Middleware)
+	}
+
+	return nil
+}
+
+// applyMiddlewareOverrides adds the middleware overrides of a module to the
+// configurable middleware. Path rules are resolved against the module's route
+// group; Global settings apply to every route the module registered.
+func (mi *Initializer) applyMiddlewareOverrides(name string, mod ConfigurableModule, routes *router.RouterGroup, cfg *config.MiddlewareConfig) {
+	overrides := mod.MiddlewareConfig()
+	if overrides == nil {
+		return
+	}
+
+	count := 0
+	patterns := make([]string, 0, len(overrides.PathRules))
+	for pattern := range overrides.PathRules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		cfg.AddRule(config.MiddlewareRule{
+			Pattern:  resolveRulePattern(routes.Prefix(), pattern),
+			Module:   name,
+			Settings: overrides.PathRules[pattern],
+		})
+		count++
+	}
+
+	if overrides.Global != nil {
+		seen := make(map[string]bool)
+		for _, route := range routes.Routes() {
+			if seen[route.Path] {
 				continue
 			}
+			seen[route.Path] = true
+			cfg.AddRule(config.MiddlewareRule{
+				Pattern:    route.Path,
+				Module:     name,
+				ModuleWide: true,
+				Settings:   *overrides.Global,
+			})
+			count++
 		}
+	}
 
-		// Setup routes
-		if routeModule, ok := mod.(interface{ Routes(*router.RouterGroup) }); ok {
-			routeModule.Routes(deps.Router)
-		}
+	mi.logger.Info("Middleware overrides applied",
+		logger.String("module", name),
+		logger.Int("rules", count))
+}
 
-		initializedModules = append(initializedModules, mod)
-		mi.logger.Info("Module initialized successfully", logger.String("module", name))
+// resolveRulePattern joins a path rule to the module's route prefix unless it
+// already starts with it
+func resolveRulePattern(prefix, pattern string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" || pattern == prefix || strings.HasPrefix(pattern, prefix+"/") {
+		return pattern
+	}
+	return prefix + "/" + strings.TrimPrefix(pattern, "/")
+}
+
+// runMigrations registers the versioned migrations of a module and applies
+// the pending ones unless auto migration is disabled
+func (mi *Initializer) runMigrations(name string, mod Module) error {
+	provider, ok := mod.(MigrationProvider)
+	if !ok || mi.migrator == nil {
+		return nil
+	}
+	if err := mi.migrator.Register(name, provider.Migrations()...); err != nil {
+		return err
+	}
+	if mi.manualMigrations {
+		return nil
+	}
+
+	applied, err := mi.migrator.Migrate(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	for _, mig := range applied {
+		mi.logger.Info("Migration applied",
+			logger.String("module", name),
+			logger.String("version", mig.Version),
+			logger.String("name", mig.Name))
+	}
+	return nil
+}
+
+// runSeeds registers the seeds of a module and runs the ones that have not run
+// yet unless auto seeding is disabled
+func (mi *Initializer) runSeeds(name string, mod Module) error {
+	if mi.seeds == nil {
+		return nil
 	}
 
-	return initializedModules
+	var seeds []database.Seed
+	if seeder, ok := mod.(Seeder); ok {
+		seeds = append(seeds, database.Seed{Name: "default", Run: seeder.Seed})
+	}
+	if provider, ok := mod.(SeedProvider); ok {
+		seeds = append(seeds, provider.Seeds()...)
+	}
+	if len(seeds) == 0 {
+		return nil
+	}
+
+	if err := mi.seeds.Register(name, seeds...); err != nil {
+		return err
+	}
+	if mi.manualMigrations || mi.manualSeeds {
+		return nil
+	}
+
+	ran, err := mi.seeds.Run(context.Background(), false, name)
+	if err != nil {
+		return err
+	}
+	for _, seed := range ran {
+		mi.logger.Info("Seed ran",
+			logger.String("module", name),
+			logger.String("seed", seed.Name))
+	}
+	return nil
+}
+
+// failedDependency returns the first dependency of a module that failed or was skipped
+func (mi *Initializer) failedDependency(mod Module) string {
+	for _, dep := range dependenciesOf(mod) {
+		if mi.failed[dep] {
+			return dep
+		}
+	}
+	return ""
 }
@@ -1,5 +1,7 @@
 package module
 
+import "base/core/config"
+
 // ConfigurableModule extends the base Module interface with middleware configuration
 type ConfigurableModule interface {
 	Module
@@ -13,6 +15,8 @@ type ConfigurableModule interface {
 type MiddlewareOverrides struct {
 	// PathRules maps URL paths to middleware settings
 	// Supports wildcards: "/api/webhooks/*" matches all webhook endpoints
+	// Paths are relative to the module's route group ("/api"); paths that
+	// already start with the group prefix are used as is
 	PathRules map[string]MiddlewareSettings
 	
 	// Global overrides apply to all routes in this module
@@ -20,59 +24,13 @@ type MiddlewareOverrides struct {
 }
 
 // MiddlewareSettings defines middleware configuration for a specific path or module
-type MiddlewareSettings struct {
-	// APIKey controls API key requirement
-	// nil = use global setting, true = require, false = skip
-	APIKey *bool `json:"api_key,omitempty"`
-	
-	// Auth controls authentication requirement
-	// nil = use global setting, true = require, false = skip
-	Auth *bool `json:"auth,omitempty"`
-	
-	// RateLimit controls rate limiting
-	// nil = use global setting, config = custom rate limit
-	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
-	
-	// Logging controls request logging
-	// nil = use global setting, true = enable, false = disable
-	Logging *bool `json:"logging,omitempty"`
-	
-	// CORS controls CORS headers
-	// nil = use global setting, true = enable, false = disable
-	CORS *bool `json:"cors,omitempty"`
-	
-	// WebhookSignature controls webhook signature verification
-	// nil = use global setting, config = custom webhook config
-	WebhookSignature *WebhookSignatureConfig `json:"webhook_signature,omitempty"`
-}
+type MiddlewareSettings = config.MiddlewareSettings
 
 // RateLimitConfig defines custom rate limiting configuration
-type RateLimitConfig struct {
-	// Requests per window
-	Requests int `json:"requests"`
-	
-	// Window duration (e.g., "1m", "1h")
-	Window string `json:"window"`
-	
-	// KeyFunc determines how to extract the rate limit key
-	// Options: "ip", "user", "api_key", or custom function name
-	KeyFunc string `json:"key_func,omitempty"`
-}
+type RateLimitConfig = config.RateLimitConfig
 
 // WebhookSignatureConfig defines webhook signature verification configuration
-type WebhookSignatureConfig struct {
-	// Provider name (e.g., "stripe", "github", "paypal")
-	Provider string `json:"provider"`
-	
-	// Header name containing the signature
-	Header string `json:"header"`
-	
-	// Secret environment variable name
-	SecretEnvVar string `json:"secret_env_var"`
-	
-	// Algorithm (e.g., "sha256", "sha1")
-	Algorithm string `json:"algorithm,omitempty"`
-}
+type WebhookSignatureConfig = config.WebhookSignatureConfig
 
 // Helper functions for creating middleware settings
 
@@ -0,0 +1,159 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"base/core/logger"
+)
+
+// DefaultLifecycleTimeout bounds how long a single module may take to start or stop
+const DefaultLifecycleTimeout = 10 * time.Second
+
+// Starter is implemented by modules that run background work (schedulers,
+// consumers, watchers). Start is called once every module has registered its routes.
+type Starter interface {
+	Start() error
+}
+
+// Stopper is implemented by modules that need to release resources on shutdown.
+// Stop is called in reverse start order.
+type Stopper interface {
+	Stop() error
+}
+
+// LifecycleTimeout can be implemented by modules that need a start/stop
+// timeout different from the lifecycle default
+type LifecycleTimeout interface {
+	LifecycleTimeout() time.Duration
+}
+
+// lifecycleEntry pairs a module with the name it was registered under
+type lifecycleEntry struct {
+	name   string
+	module Module
+}
+
+// Lifecycle starts and stops initialized modules in a deterministic order
+type Lifecycle struct {
+	logger  logger.Logger
+	timeout time.Duration
+	modules []lifecycleEntry
+	started []lifecycleEntry
+	mu      sync.Mutex
+}
+
+// NewLifecycle creates a new module lifecycle manager. A non-positive timeout
+// falls back to DefaultLifecycleTimeout.
+func NewLifecycle(log logger.Logger, timeout time.Duration) *Lifecycle {
+	if timeout <= 0 {
+		timeout = DefaultLifecycleTimeout
+	}
+	return &Lifecycle{
+		logger:  log,
+		timeout: timeout,
+	}
+}
+
+// Add records an initialized module. Modules are started in the order they are added.
+func (l *Lifecycle) Add(name string, mod Module) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.modules = append(l.modules, lifecycleEntry{name: name, module: mod})
+}
+
+// StartAll calls Start on every module implementing Starter. A failing module is
+// reported and skipped; the remaining modules are still started.
+func (l *Lifecycle) StartAll(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, entry := range l.modules {
+		starter, ok := entry.module.(Starter)
+		if !ok {
+			// Modules without Start still need Stop on shutdown
+			l.started = append(l.started, entry)
+			continue
+		}
+
+		timeout := l.timeoutFor(entry.module)
+		if err := runWithTimeout(ctx, timeout, starter.Start); err != nil {
+			l.logger.Error("Failed to start module",
+				logger.String("module", entry.name),
+				logger.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("start %s: %w", entry.name, err))
+			continue
+		}
+
+		l.started = append(l.started, entry)
+		l.logger.Info("Module started", logger.String("module", entry.name))
+	}
+
+	return errors.Join(errs...)
+}
+
+// StopAll calls Stop on every started module implementing Stopper, in reverse
+// start order. Every module is given a chance to stop even if others fail.
+func (l *Lifecycle) StopAll(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for i := len(l.started) - 1; i >= 0; i-- {
+		entry := l.started[i]
+		stopper, ok := entry.module.(Stopper)
+		if !ok {
+			continue
+		}
+
+		timeout := l.timeoutFor(entry.module)
+		if err := runWithTimeout(ctx, timeout, stopper.Stop); err != nil {
+			l.logger.Error("Failed to stop module",
+				logger.String("module", entry.name),
+				logger.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("stop %s: %w", entry.name, err))
+			continue
+		}
+
+		l.logger.Info("Module stopped", logger.String("module", entry.name))
+	}
+	l.started = nil
+
+	return errors.Join(errs...)
+}
+
+// timeoutFor returns the start/stop timeout for a module
+func (l *Lifecycle) timeoutFor(mod Module) time.Duration {
+	if t, ok := mod.(LifecycleTimeout); ok && t.LifecycleTimeout() > 0 {
+		return t.LifecycleTimeout()
+	}
+	return l.timeout
+}
+
+// runWithTimeout runs fn and gives up once the timeout or parent context expires.
+// Panics inside fn are converted to errors.
+func runWithTimeout(ctx context.Context, timeout time.Duration, fn func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+	}
+}
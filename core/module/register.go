@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sync"
 
+	"base/core/database"
 	"base/core/router"
 	"gorm.io/gorm"
 )
@@ -42,10 +43,41 @@ func (DefaultModule) GetModels() []any {
 }
 
 // Seeder is an interface that modules can implement to seed the database.
+// Seed runs once per database, after the module is migrated, inside a
+// transaction, and is tracked in the schema_seeds table as "<module>:default".
 type Seeder interface {
 	Seed(*gorm.DB) error
 }
 
+// SeedProvider is an interface that modules can implement to register several
+// named seeds, optionally scoped to environments (e.g. demo data only in
+// development). They run after Seed, in order.
+type SeedProvider interface {
+	Seeds() []database.Seed
+}
+
+// MigrationProvider is an interface that modules can implement to register
+// versioned migrations. They run after Migrate, in version order, and are
+// tracked in the schema_migrations table.
+type MigrationProvider interface {
+	Migrations() []database.Migration
+}
+
+// ServiceProvider is an interface that modules can implement to provide
+// services to other modules. ProvideServices runs for every module before any
+// module is initialized, so it should register values built in the module
+// constructor or lazy factories (see Provide).
+type ServiceProvider interface {
+	ProvideServices(*Services) error
+}
+
+// ServiceConsumer is an interface that modules can implement to resolve the
+// services of other modules. ResolveServices runs right before Init; a
+// service that no module provides fails the module at startup.
+type ServiceConsumer interface {
+	ResolveServices(*Services) error
+}
+
 // ModuleFactory is a function that creates a module with dependencies
 type ModuleFactory func(deps Dependencies) Module
 
@@ -0,0 +1,270 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrServiceNotProvided is returned when a service is resolved that no
+// module provides
+var ErrServiceNotProvided = errors.New("service not provided")
+
+// Services is a typed service container shared by modules. Modules register
+// services with Provide or ProvideValue, keyed by a type (usually an
+// interface), and other modules look them up with Resolve:
+//
+//	// users module
+//	func (m *UsersModule) ProvideServices(s *module.Services) error {
+//		return module.ProvideValue[*UserService](s, m.Service)
+//	}
+//
+//	// posts module
+//	userService, err := module.Resolve[*users.UserService](s)
+//
+// Services are constructed once, on first use, and may be resolved from
+// several goroutines: a resolution needing a service that another one is
+// constructing waits for it. Resolving a service whose construction depends
+// on itself returns an error naming the cycle, including when the cycle
+// spans concurrent resolutions.
+type Services struct {
+	container  *serviceContainer
+	path       []reflect.Type     // services being constructed by this resolution
+	owner      string             // module using this view, for error messages
+	resolution *serviceResolution // resolution this view constructs services for
+}
+
+// serviceContainer holds the providers shared by all views of a Services
+type serviceContainer struct {
+	mu        sync.Mutex // guards providers and their construction state
+	providers map[reflect.Type]*serviceProvider
+}
+
+// serviceProvider constructs and caches one service. Factories run without
+// holding the container lock.
+type serviceProvider struct {
+	module   string
+	factory  func(*Services) (any, error)
+	built    bool
+	instance any
+	builder  *serviceResolution // resolution constructing the service, if any
+	done     chan struct{}      // closed when builder finishes
+}
+
+// serviceResolution is one top-level Resolve call and the services it
+// constructs on the way
+type serviceResolution struct {
+	waiting *serviceProvider // service constructed by another resolution it waits for
+}
+
+// waitsFor reports whether waiting for provider would wait, through the
+// chain of resolutions constructing and waiting, for resolution itself
+func (p *serviceProvider) waitsFor(resolution *serviceResolution) bool {
+	for ; p != nil && p.builder != nil; p = p.builder.waiting {
+		if p.builder == resolution {
+			return true
+		}
+	}
+	return false
+}
+
+// NewServices creates an empty service container
+func NewServices() *Services {
+	return &Services{
+		container: &serviceContainer{providers: make(map[reflect.Type]*serviceProvider)},
+	}
+}
+
+// ForModule returns a view of the container that attributes the services it
+// provides and the missing services it resolves to a module
+func (s *Services) ForModule(name string) *Services {
+	return &Services{container: s.container, path: s.path, owner: name, resolution: s.resolution}
+}
+
+// Provide registers a factory for the service of type T. The factory runs the
+// first time the service is resolved and may resolve other services.
+func Provide[T any](s *Services, factory func(*Services) (T, error)) error {
+	if factory == nil {
+		return fmt.Errorf("service %s: factory cannot be nil", serviceType[T]())
+	}
+	return s.provide(serviceType[T](), func(services *Services) (any, error) {
+		return factory(services)
+	})
+}
+
+// ProvideValue registers an already constructed service of type T
+func ProvideValue[T any](s *Services, value T) error {
+	return s.provide(serviceType[T](), func(*Services) (any, error) {
+		return value, nil
+	})
+}
+
+// Resolve returns the service of type T, constructing it on first use. It
+// fails with ErrServiceNotProvided if no module provides T.
+func Resolve[T any](s *Services) (T, error) {
+	var zero T
+	instance, err := s.resolve(serviceType[T]())
+	if err != nil {
+		return zero, err
+	}
+	return instance.(T), nil
+}
+
+// MustResolve is like Resolve but panics if the service cannot be resolved
+func MustResolve[T any](s *Services) T {
+	service, err := Resolve[T](s)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// Has reports whether a service of type T is provided
+func Has[T any](s *Services) bool {
+	s.container.mu.Lock()
+	defer s.container.mu.Unlock()
+	_, ok := s.container.providers[serviceType[T]()]
+	return ok
+}
+
+// Provided returns the provided service types and the modules providing
+// them, keyed by type name
+func (s *Services) Provided() map[string]string {
+	s.container.mu.Lock()
+	defer s.container.mu.Unlock()
+
+	provided := make(map[string]string, len(s.container.providers))
+	for typ, provider := range s.container.providers {
+		provided[typ.String()] = provider.module
+	}
+	return provided
+}
+
+// provide registers a provider, rejecting duplicates
+func (s *Services) provide(typ reflect.Type, factory func(*Services) (any, error)) error {
+	s.container.mu.Lock()
+	defer s.container.mu.Unlock()
+
+	if existing, ok := s.container.providers[typ]; ok {
+		return fmt.Errorf("service %s is already provided by %s", typ, describeModule(existing.module))
+	}
+	s.container.providers[typ] = &serviceProvider{module: s.owner, factory: factory}
+	return nil
+}
+
+// resolve constructs or returns the cached service of a type
+func (s *Services) resolve(typ reflect.Type) (any, error) {
+	path := append(append([]reflect.Type(nil), s.path...), typ)
+	for i, building := range s.path {
+		if building == typ {
+			return nil, fmt.Errorf("service dependency cycle: %s", formatServicePath(path[i:]))
+		}
+	}
+
+	resolution := s.resolution
+	if resolution == nil {
+		resolution = &serviceResolution{}
+	}
+
+	s.container.mu.Lock()
+	provider, ok := s.container.providers[typ]
+	if !ok {
+		s.container.mu.Unlock()
+		return nil, s.notProvided(typ)
+	}
+	for provider.builder != nil {
+		// Waiting on a resolution that waits for this one would never end
+		if provider.waitsFor(resolution) {
+			s.container.mu.Unlock()
+			return nil, fmt.Errorf("service dependency cycle: %s (%s is being constructed by a concurrent resolution that depends on it)",
+				formatServicePath(path), typ)
+		}
+		resolution.waiting = provider
+		done := provider.done
+		s.container.mu.Unlock()
+		<-done
+		s.container.mu.Lock()
+		resolution.waiting = nil
+	}
+	if provider.built {
+		instance := provider.instance
+		s.container.mu.Unlock()
+		return instance, nil
+	}
+	provider.builder, provider.done = resolution, make(chan struct{})
+	s.container.mu.Unlock()
+
+	return s.build(typ, provider, &Services{container: s.container, path: path, owner: provider.module, resolution: resolution})
+}
+
+// build runs the factory of a provider claimed by a resolution and caches
+// the service. Waiting resolutions retry the construction if it fails.
+func (s *Services) build(typ reflect.Type, provider *serviceProvider, services *Services) (instance any, err error) {
+	defer func() {
+		s.container.mu.Lock()
+		defer s.container.mu.Unlock()
+		// A panicking factory leaves both results unset
+		if err == nil && instance != nil {
+			provider.instance, provider.built = instance, true
+		}
+		close(provider.done)
+		provider.builder, provider.done = nil, nil
+	}()
+
+	instance, err = provider.factory(services)
+	if err != nil {
+		return nil, fmt.Errorf("service %s (%s): %w", typ, describeModule(provider.module), err)
+	}
+	if instance == nil {
+		return nil, fmt.Errorf("service %s (%s): factory returned nil", typ, describeModule(provider.module))
+	}
+	return instance, nil
+}
+
+// notProvided builds the error for a missing service, listing the services
+// that are available
+func (s *Services) notProvided(typ reflect.Type) error {
+	var details string
+	if s.owner != "" {
+		details += " (required by " + s.owner + ")"
+	}
+	if len(s.path) > 0 {
+		details += " while constructing " + formatServicePath(s.path)
+	}
+
+	var available []string
+	for name := range s.Provided() {
+		available = append(available, name)
+	}
+	if len(available) > 0 {
+		sort.Strings(available)
+		details += "; available: " + strings.Join(available, ", ")
+	}
+
+	return fmt.Errorf("%w: %s%s", ErrServiceNotProvided, typ, details)
+}
+
+// serviceType returns the registry key for T
+func serviceType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// formatServicePath renders a chain of services as "a -> b -> c"
+func formatServicePath(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, typ := range path {
+		names[i] = typ.String()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// describeModule names the provider of a service
+func describeModule(name string) string {
+	if name == "" {
+		return "the application"
+	}
+	return "module " + name
+}
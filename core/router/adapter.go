@@ -0,0 +1,206 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// contextKey is the request context key of the router Context
+type contextKey struct{}
+
+// requestContext exposes a router Context to net/http code through the
+// request context: FromRequest finds the Context, and string keys stored
+// with Context.Set are returned by Value.
+type requestContext struct {
+	context.Context
+	c *Context
+}
+
+// Value returns the router Context, a value stored with Context.Set or a
+// value of the parent context
+func (rc requestContext) Value(key any) any {
+	if key == (contextKey{}) {
+		return rc.c
+	}
+	if name, ok := key.(string); ok {
+		if value, exists := rc.c.Get(name); exists {
+			return value
+		}
+	}
+	return rc.Context.Value(key)
+}
+
+// FromRequest returns the router Context of a request passed to a net/http
+// handler or middleware by WrapHandler, WrapMiddleware or Mount. Like the
+// Context itself, it must not be used after the request has been served.
+func FromRequest(r *http.Request) (*Context, bool) {
+	c, ok := r.Context().Value(contextKey{}).(*Context)
+	return c, ok
+}
+
+// httpRequest returns the request with the Context attached to its context
+func (c *Context) httpRequest() *http.Request {
+	if attached, _ := c.Request.Context().Value(contextKey{}).(*Context); attached == c {
+		return c.Request
+	}
+	return c.Request.WithContext(requestContext{Context: c.Request.Context(), c: c})
+}
+
+// adopt continues with the request and writer a net/http middleware passed
+// on, so that values it added to the request context reach the handler
+func (c *Context) adopt(w http.ResponseWriter, r *http.Request) {
+	c.Request = r
+	if w == http.ResponseWriter(c.Writer) {
+		return
+	}
+	if rw, ok := w.(ResponseWriter); ok {
+		c.Writer = rw
+		return
+	}
+	c.Writer = &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WrapHandler adapts a net/http handler to a HandlerFunc
+//
+//	r.GET("/docs/*any", router.WrapHandler(httpSwagger.Handler()))
+func WrapHandler(h http.Handler) HandlerFunc {
+	return func(c *Context) error {
+		h.ServeHTTP(c.Writer, c.httpRequest())
+		return nil
+	}
+}
+
+// WrapHandlerFunc adapts a net/http handler function to a HandlerFunc
+func WrapHandlerFunc(fn http.HandlerFunc) HandlerFunc {
+	return WrapHandler(fn)
+}
+
+// WrapMiddleware adapts net/http middleware to a MiddlewareFunc. Request
+// context values the middleware adds are available through Context.Value,
+// and a writer it wraps receives the response.
+//
+//	r.Use(router.WrapMiddleware(chimw.RealIP))
+func WrapMiddleware(mw func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		// Build the net/http chain once per route, like native middleware
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, _ := FromRequest(r)
+			c.adopt(w, r)
+			c.wrapErr = next(c)
+		}))
+
+		return func(c *Context) error {
+			request, writer := c.Request, c.Writer
+			defer func() {
+				c.Request, c.Writer = request, writer
+			}()
+
+			c.wrapErr = nil
+			h.ServeHTTP(c.Writer, c.httpRequest())
+			err := c.wrapErr
+			c.wrapErr = nil
+			return err
+		}
+	}
+}
+
+// HTTPMiddleware adapts a MiddlewareFunc to net/http middleware, e.g. to
+// protect handlers served outside the router. Errors the middleware returns
+// are answered with 500 Internal Server Error.
+func HTTPMiddleware(mw MiddlewareFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handler := mw(func(c *Context) error {
+			next.ServeHTTP(c.Writer, c.httpRequest())
+			return nil
+		})
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, ok := FromRequest(r)
+			if ok {
+				request, writer := c.Request, c.Writer
+				defer func() {
+					c.Request, c.Writer = request, writer
+				}()
+				c.adopt(w, r)
+			} else {
+				c = &Context{}
+				c.reset(w, r)
+			}
+
+			if err := handler(c); err != nil {
+				c.Error(http.StatusInternalServerError, err)
+			}
+		})
+	}
+}
+
+// HTTPHandler adapts a HandlerFunc to a net/http handler
+func HTTPHandler(handler HandlerFunc) http.Handler {
+	return HTTPMiddleware(func(HandlerFunc) HandlerFunc {
+		return handler
+	})(nil)
+}
+
+// mountMethods are the methods Mount registers
+var mountMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// Mount serves every request below prefix with a net/http handler, which sees
+// the path with the prefix stripped, like http.StripPrefix:
+//
+//	r.Mount("/debug/pprof", http.DefaultServeMux)
+//	r.Mount("/metrics", promhttp.Handler())
+func (r *Router) Mount(prefix string, h http.Handler, middleware ...MiddlewareFunc) {
+	r.mount("", "", "", prefix, h, middleware)
+}
+
+// Mount serves every request below prefix, relative to the group, with a
+// net/http handler. The group's middleware applies.
+func (g *RouterGroup) Mount(prefix string, h http.Handler, middleware ...MiddlewareFunc) {
+	allMiddleware := append(append([]MiddlewareFunc{}, g.middleware...), middleware...)
+	g.router.mount(g.host, g.module, g.prefix, prefix, h, allMiddleware)
+}
+
+// mount registers the routes of a mounted handler for every method
+func (r *Router) mount(host, module, base, prefix string, h http.Handler, middleware []MiddlewareFunc) {
+	prefix = strings.TrimSuffix(base+"/"+strings.Trim(prefix, "/"), "/")
+	if prefix == "" {
+		panic("router: Mount needs a prefix; serve the handler directly instead")
+	}
+
+	handler := func(c *Context) error {
+		req := c.httpRequest()
+		stripped := new(http.Request)
+		*stripped = *req
+		stripped.URL = new(url.URL)
+		*stripped.URL = *req.URL
+		stripped.URL.Path = stripMountPrefix(req.URL.Path, prefix)
+		if req.URL.RawPath != "" {
+			if strings.HasPrefix(req.URL.RawPath, prefix) {
+				stripped.URL.RawPath = stripMountPrefix(req.URL.RawPath, prefix)
+			} else {
+				stripped.URL.RawPath = ""
+			}
+		}
+		h.ServeHTTP(c.Writer, stripped)
+		return nil
+	}
+
+	for _, method := range mountMethods {
+		for _, path := range []string{prefix, prefix + "/*path"} {
+			r.handle(&Route{Method: method, Path: path, Module: module, Host: host}, handler, middleware)
+		}
+	}
+}
+
+// stripMountPrefix removes the mount prefix from a path, keeping it rooted
+func stripMountPrefix(path, prefix string) string {
+	if rest := strings.TrimPrefix(path, prefix); rest != "" {
+		return rest
+	}
+	return "/"
+}
@@ -0,0 +1,594 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BatchRequest is the body of a batch request. A bare array of items is
+// accepted too and runs in parallel.
+type BatchRequest struct {
+	// Sequential runs the items one after the other in the given order
+	Sequential bool        `json:"sequential,omitempty"`
+	Requests   []BatchItem `json:"requests"`
+}
+
+// BatchItem is a request of a batch. Its path, header values and body may
+// refer to the response of an earlier item with {{id.status}},
+// {{id.headers.Name}}, {{id.body}} or {{id.body.field.0.name}}; the item
+// then depends on it. A body value that is only a reference takes the type
+// of the referenced value.
+type BatchItem struct {
+	Id        string            `json:"id,omitempty"` // defaults to the item's index
+	Method    string            `json:"method,omitempty"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      json.RawMessage   `json:"body,omitempty"`
+	DependsOn []string          `json:"depends_on,omitempty"`
+}
+
+// BatchResult is the response to an item of a batch. Items that could not be
+// dispatched, or whose dependencies failed, have an Error.
+type BatchResult struct {
+	Id      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// BatchResponse is the response to a batch request, with a result per item
+// in request order
+type BatchResponse struct {
+	Responses []BatchResult `json:"responses"`
+}
+
+// BatchOption configures the batch handler
+type BatchOption func(*batchConfig)
+
+// batchConfig holds the batch handler options
+type batchConfig struct {
+	maxRequests int
+	concurrency int
+}
+
+// WithBatchLimit sets the largest number of items in a batch, 20 by default
+func WithBatchLimit(n int) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.maxRequests = n
+	}
+}
+
+// WithBatchConcurrency sets how many items of a parallel batch run at the
+// same time, 8 by default
+func WithBatchConcurrency(n int) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.concurrency = n
+	}
+}
+
+// Caller headers copied to every item. The caller's credentials override
+// the item's own, other headers only fill in what the item does not set.
+var (
+	batchAuthHeaders    = []string{"Authorization", "Cookie", "X-Api-Key"}
+	batchContextHeaders = []string{"Accept-Language", "User-Agent", "X-Forwarded-For", "X-Forwarded-Proto", "X-Real-Ip"}
+)
+
+// batchRefPattern matches a reference to the response of another item
+var batchRefPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)((?:\.[A-Za-z0-9_-]+)+)\s*\}\}`)
+
+// batchQuotedRefPattern matches a JSON string holding only a reference
+var batchQuotedRefPattern = regexp.MustCompile(`"` + batchRefPattern.String() + `"`)
+
+// Batch returns a handler running several requests in one round trip. Each
+// item is dispatched in-process through the router with the caller's
+// credentials, so it passes the same middleware as a request of its own:
+//
+//	r.POST("/api/batch", r.Batch())
+//
+//	POST /api/batch
+//	{"requests": [
+//		{"id": "me", "method": "GET", "path": "/api/profile"},
+//		{"id": "posts", "method": "GET", "path": "/api/users/{{me.body.id}}/posts"}
+//	]}
+//
+// Items run in parallel unless the batch is sequential, each once its
+// dependencies have completed. An item whose dependency failed with a 4xx or
+// 5xx status is not run and gets 424 Failed Dependency.
+func (r *Router) Batch(opts ...BatchOption) HandlerFunc {
+	cfg := &batchConfig{maxRequests: 20, concurrency: 8}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c *Context) error {
+		if c.Request.Context().Value(batchKey{}) != nil {
+			return c.JSON(http.StatusBadRequest, typedError{Error: "batch requests cannot be nested"})
+		}
+
+		batch, err := decodeBatch(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, typedError{Error: err.Error()})
+		}
+		if len(batch.Requests) == 0 {
+			return c.JSON(http.StatusBadRequest, typedError{Error: "batch has no requests"})
+		}
+		if cfg.maxRequests > 0 && len(batch.Requests) > cfg.maxRequests {
+			return c.JSON(http.StatusBadRequest, typedError{
+				Error: fmt.Sprintf("batch has %d requests, at most %d are allowed", len(batch.Requests), cfg.maxRequests),
+			})
+		}
+
+		run, err := newBatchRun(r, c, batch)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, typedError{Error: err.Error()})
+		}
+		if batch.Sequential {
+			run.sequential()
+		} else {
+			run.parallel(cfg.concurrency)
+		}
+		return c.JSON(http.StatusOK, BatchResponse{Responses: run.results})
+	}
+}
+
+// batchKey marks the request context of a batch's sub-requests, which may
+// not run another batch
+type batchKey struct{}
+
+// decodeBatch reads a batch request or a bare array of items
+func decodeBatch(c *Context) (*BatchRequest, error) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	batch := &BatchRequest{}
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &batch.Requests)
+	} else {
+		err = json.Unmarshal(data, batch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch: %w", err)
+	}
+	return batch, nil
+}
+
+// batchRun executes the items of a batch
+type batchRun struct {
+	router  *Router
+	c       *Context
+	items   []BatchItem
+	index   map[string]int // item id -> position
+	deps    [][]int
+	results []BatchResult
+	done    []chan struct{}
+}
+
+// newBatchRun validates the items and resolves their dependencies. Invalid
+// items get an error result and are not run.
+func newBatchRun(r *Router, c *Context, batch *BatchRequest) (*batchRun, error) {
+	run := &batchRun{
+		router:  r,
+		c:       c,
+		items:   batch.Requests,
+		index:   make(map[string]int, len(batch.Requests)),
+		deps:    make([][]int, len(batch.Requests)),
+		results: make([]BatchResult, len(batch.Requests)),
+		done:    make([]chan struct{}, len(batch.Requests)),
+	}
+
+	for i := range run.items {
+		item := &run.items[i]
+		if item.Id == "" {
+			item.Id = strconv.Itoa(i)
+		}
+		if _, exists := run.index[item.Id]; exists {
+			return nil, fmt.Errorf("duplicate batch request id %q", item.Id)
+		}
+		run.index[item.Id] = i
+		run.results[i].Id = item.Id
+		run.done[i] = make(chan struct{})
+	}
+
+	for i := range run.items {
+		if err := run.resolveDeps(i, batch.Sequential); err != nil {
+			run.fail(i, http.StatusBadRequest, err.Error())
+		}
+	}
+	if !batch.Sequential {
+		run.failCycles()
+	}
+	return run, nil
+}
+
+// resolveDeps collects the items an item depends on, explicitly or through
+// references, and checks that it can be dispatched
+func (run *batchRun) resolveDeps(i int, sequential bool) error {
+	item := &run.items[i]
+	item.Method = strings.ToUpper(item.Method)
+	if item.Method == "" {
+		item.Method = http.MethodGet
+	}
+	if !strings.HasPrefix(item.Path, "/") {
+		return errors.New("path must begin with '/'")
+	}
+
+	ids := append([]string(nil), item.DependsOn...)
+	texts := []string{item.Path, string(item.Body)}
+	for _, value := range item.Headers {
+		texts = append(texts, value)
+	}
+	for _, text := range texts {
+		for _, match := range batchRefPattern.FindAllStringSubmatch(text, -1) {
+			ids = append(ids, match[1])
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		dep, ok := run.index[id]
+		switch {
+		case !ok:
+			return fmt.Errorf("unknown dependency %q", id)
+		case dep == i:
+			return errors.New("request depends on itself")
+		case sequential && dep > i:
+			return fmt.Errorf("dependency %q runs later in a sequential batch", id)
+		}
+		if !seen[dep] {
+			seen[dep] = true
+			run.deps[i] = append(run.deps[i], dep)
+		}
+	}
+	return nil
+}
+
+// failCycles fails the items whose dependencies can never complete
+func (run *batchRun) failCycles() {
+	pending := make([]int, len(run.items))
+	dependents := make([][]int, len(run.items))
+	for i, deps := range run.deps {
+		pending[i] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], i)
+		}
+	}
+
+	var ready []int
+	for i := range run.items {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	resolved := 0
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		resolved++
+		for _, dependent := range dependents[i] {
+			if pending[dependent]--; pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if resolved == len(run.items) {
+		return
+	}
+
+	for i := range run.items {
+		if pending[i] > 0 && run.results[i].Status == 0 {
+			run.fail(i, http.StatusBadRequest, "circular dependency")
+		}
+	}
+}
+
+// fail records an item that is not run
+func (run *batchRun) fail(i, status int, message string) {
+	run.results[i].Status = status
+	run.results[i].Error = message
+}
+
+// sequential runs the items in order
+func (run *batchRun) sequential() {
+	for i := range run.items {
+		run.run(i)
+	}
+}
+
+// parallel runs the items as soon as their dependencies have completed
+func (run *batchRun) parallel(concurrency int) {
+	if concurrency <= 0 {
+		concurrency = len(run.items)
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := range run.items {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if run.results[i].Status != 0 {
+				// Failed validation; in a cycle its dependencies never finish
+				run.run(i)
+				return
+			}
+			for _, dep := range run.deps[i] {
+				<-run.done[dep]
+			}
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			run.run(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// run dispatches an item unless it failed validation or a dependency failed
+func (run *batchRun) run(i int) {
+	defer close(run.done[i])
+	if run.results[i].Status != 0 {
+		return
+	}
+	for _, dep := range run.deps[i] {
+		if status := run.results[dep].Status; status >= http.StatusBadRequest {
+			run.fail(i, http.StatusFailedDependency, fmt.Sprintf("dependency %q failed with status %d", run.items[dep].Id, status))
+			return
+		}
+	}
+
+	req, err := run.request(&run.items[i])
+	if err != nil {
+		run.fail(i, http.StatusBadRequest, err.Error())
+		return
+	}
+	run.results[i] = run.dispatch(req)
+	run.results[i].Id = run.items[i].Id
+}
+
+// request builds the sub-request of an item, with the caller's credentials
+func (run *batchRun) request(item *BatchItem) (*http.Request, error) {
+	path, err := run.substitute(item.Path, func(value any) string {
+		return url.PathEscape(refString(value))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	for name, value := range item.Headers {
+		resolved, err := run.substitute(value, refString)
+		if err != nil {
+			return nil, err
+		}
+		header.Set(name, resolved)
+	}
+
+	body := io.Reader(http.NoBody)
+	if payload := bytes.TrimSpace(item.Body); len(payload) > 0 && string(payload) != "null" {
+		resolved, err := run.substituteJSON(string(payload))
+		if err != nil {
+			return nil, err
+		}
+		contentType := header.Get("Content-Type")
+		var text string
+		if contentType != "" && !strings.Contains(contentType, "json") && json.Unmarshal([]byte(resolved), &text) == nil {
+			// A string body with a non-JSON type is sent as is, e.g. a form
+			resolved = text
+		} else if contentType == "" {
+			header.Set("Content-Type", "application/json")
+		}
+		body = strings.NewReader(resolved)
+	}
+
+	caller := run.c.Request
+	ctx := context.WithValue(caller.Context(), batchKey{}, true)
+	req, err := http.NewRequestWithContext(ctx, item.Method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	for _, name := range batchAuthHeaders {
+		if values := caller.Header.Values(name); len(values) > 0 {
+			req.Header[name] = values
+		}
+	}
+	for _, name := range batchContextHeaders {
+		if values := caller.Header.Values(name); len(values) > 0 && req.Header.Get(name) == "" {
+			req.Header[name] = values
+		}
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	req.Host = caller.Host
+	req.RemoteAddr = caller.RemoteAddr
+	req.RequestURI = path
+	req.TLS = caller.TLS
+	return req, nil
+}
+
+// dispatch serves a sub-request through the router
+func (run *batchRun) dispatch(req *http.Request) (result BatchResult) {
+	rec := &batchRecorder{header: make(http.Header), status: http.StatusOK}
+	defer func() {
+		// A panic escaping the sub-request's middleware must not take the
+		// batch down
+		if p := recover(); p != nil {
+			result = BatchResult{Status: http.StatusInternalServerError, Error: "request failed"}
+			if p == http.ErrAbortHandler {
+				result = BatchResult{Status: http.StatusGatewayTimeout, Error: "request timed out"}
+			}
+		}
+	}()
+
+	run.router.ServeHTTP(rec, req)
+	return rec.result()
+}
+
+// substitute replaces the references in a string with the referenced values
+func (run *batchRun) substitute(text string, format func(any) string) (string, error) {
+	var err error
+	replaced := batchRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		value, refErr := run.lookup(batchRefPattern.FindStringSubmatch(ref))
+		if refErr != nil {
+			err = refErr
+			return ref
+		}
+		return format(value)
+	})
+	return replaced, err
+}
+
+// substituteJSON replaces the references in a JSON body: a string holding
+// only a reference becomes the referenced JSON value, references inside
+// other strings are replaced by their text
+func (run *batchRun) substituteJSON(body string) (string, error) {
+	var err error
+	body = batchQuotedRefPattern.ReplaceAllStringFunc(body, func(ref string) string {
+		value, refErr := run.lookup(batchQuotedRefPattern.FindStringSubmatch(ref))
+		if refErr != nil {
+			err = refErr
+			return ref
+		}
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return run.substitute(body, func(value any) string {
+		encoded, _ := json.Marshal(refString(value))
+		return string(encoded[1 : len(encoded)-1])
+	})
+}
+
+// lookup returns the value a reference match points to
+func (run *batchRun) lookup(match []string) (any, error) {
+	ref := strings.Trim(match[0], `"{} `)
+	result := run.results[run.index[match[1]]]
+	fields := strings.Split(strings.TrimPrefix(match[2], "."), ".")
+
+	switch fields[0] {
+	case "status":
+		if len(fields) == 1 {
+			return result.Status, nil
+		}
+	case "headers":
+		if len(fields) == 2 {
+			return result.Headers[http.CanonicalHeaderKey(fields[1])], nil
+		}
+	case "body":
+		var value any
+		decoder := json.NewDecoder(bytes.NewReader(result.Body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("reference %q: response body is not JSON", ref)
+		}
+		for _, field := range fields[1:] {
+			switch v := value.(type) {
+			case map[string]any:
+				value = v[field]
+			case []any:
+				n, err := strconv.Atoi(field)
+				if err != nil || n < 0 || n >= len(v) {
+					return nil, fmt.Errorf("reference %q: no element %s", ref, field)
+				}
+				value = v[n]
+			default:
+				return nil, fmt.Errorf("reference %q: no field %s", ref, field)
+			}
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid reference %q", ref)
+}
+
+// refString returns the text of a referenced value
+func refString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// batchRecorder records the response to a sub-request
+type batchRecorder struct {
+	header  http.Header
+	sent    http.Header
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+// Header returns the response headers
+func (w *batchRecorder) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status and the headers sent with it
+func (w *batchRecorder) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	w.status = code
+	w.sent = w.header.Clone()
+	w.written = true
+}
+
+// Write records the data
+func (w *batchRecorder) Write(data []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(data)
+}
+
+// Flush has nothing to send; the response is complete when the handler returns
+func (w *batchRecorder) Flush() {}
+
+// result converts the recorded response, keeping JSON bodies as they are
+func (w *batchRecorder) result() BatchResult {
+	sent := w.sent
+	if sent == nil {
+		sent = w.header
+	}
+
+	result := BatchResult{Status: w.status, Headers: make(map[string]string, len(sent))}
+	for name, values := range sent {
+		if name != "Content-Length" {
+			result.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	body := w.body.Bytes()
+	switch {
+	case len(body) == 0:
+	case strings.Contains(sent.Get("Content-Type"), "json") && json.Valid(body):
+		result.Body = json.RawMessage(bytes.TrimSpace(body))
+	default:
+		result.Body, _ = json.Marshal(string(body))
+	}
+	return result
+}
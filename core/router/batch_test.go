@@ -0,0 +1,230 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// batchRouter returns a router with a batch endpoint and routes to call
+func batchRouter() *Router {
+	r := New()
+	r.POST("/batch", r.Batch())
+	r.GET("/me", func(c *Context) error {
+		c.SetHeader("X-Token", "secret")
+		return c.JSON(http.StatusOK, map[string]any{"id": 7, "name": "ada", "tags": []string{"x", "y"}})
+	})
+	r.GET("/users/:id", func(c *Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"id": c.Param("id"), "token": c.Header("X-Token")})
+	})
+	r.POST("/echo", func(c *Context) error {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, json.RawMessage(body))
+	})
+	r.GET("/missing", func(c *Context) error {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	})
+	return r
+}
+
+// runBatch posts a batch and returns its results by id
+func runBatch(t *testing.T, r *Router, body string) map[string]BatchResult {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("batch status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var resp BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	results := make(map[string]BatchResult, len(resp.Responses))
+	for _, result := range resp.Responses {
+		results[result.Id] = result
+	}
+	return results
+}
+
+func TestBatchDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		batch  string
+		status map[string]int
+		error  map[string]string // substring of the item's error
+	}{
+		{
+			name: "cycle",
+			batch: `[
+				{"id": "a", "path": "/me", "depends_on": ["b"]},
+				{"id": "b", "path": "/me", "depends_on": ["a"]},
+				{"id": "c", "path": "/me"}
+			]`,
+			status: map[string]int{"a": 400, "b": 400, "c": 200},
+			error:  map[string]string{"a": "circular dependency", "b": "circular dependency"},
+		},
+		{
+			name: "cycle through references",
+			batch: `[
+				{"id": "a", "path": "/users/{{c.body.id}}"},
+				{"id": "b", "path": "/users/{{a.body.id}}"},
+				{"id": "c", "path": "/users/{{b.body.id}}"}
+			]`,
+			status: map[string]int{"a": 400, "b": 400, "c": 400},
+			error:  map[string]string{"a": "circular dependency"},
+		},
+		{
+			name: "dependent of a cycle",
+			batch: `[
+				{"id": "a", "path": "/me", "depends_on": ["b"]},
+				{"id": "b", "path": "/me", "depends_on": ["a"]},
+				{"id": "c", "path": "/me", "depends_on": ["a"]}
+			]`,
+			status: map[string]int{"a": 400, "b": 400, "c": 400},
+			error:  map[string]string{"c": "circular dependency"},
+		},
+		{
+			name:   "self dependency",
+			batch:  `[{"id": "a", "path": "/me", "depends_on": ["a"]}]`,
+			status: map[string]int{"a": 400},
+			error:  map[string]string{"a": "depends on itself"},
+		},
+		{
+			name:   "unknown dependency",
+			batch:  `[{"id": "a", "path": "/users/{{nope.body.id}}"}]`,
+			status: map[string]int{"a": 400},
+			error:  map[string]string{"a": `unknown dependency "nope"`},
+		},
+		{
+			name: "failed dependency",
+			batch: `[
+				{"id": "a", "path": "/missing"},
+				{"id": "b", "path": "/me", "depends_on": ["a"]},
+				{"id": "c", "path": "/me", "depends_on": ["b"]}
+			]`,
+			status: map[string]int{"a": 404, "b": 424, "c": 424},
+			error:  map[string]string{"b": `dependency "a" failed with status 404`},
+		},
+		{
+			name: "sequential batch refers to a later item",
+			batch: `{"sequential": true, "requests": [
+				{"id": "a", "path": "/users/{{b.body.id}}"},
+				{"id": "b", "path": "/me"}
+			]}`,
+			status: map[string]int{"a": 400, "b": 200},
+			error:  map[string]string{"a": "runs later in a sequential batch"},
+		},
+		{
+			name:   "nested batch",
+			batch:  `[{"id": "a", "method": "POST", "path": "/batch", "body": []}]`,
+			status: map[string]int{"a": 400},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := runBatch(t, batchRouter(), tt.batch)
+			for id, status := range tt.status {
+				if results[id].Status != status {
+					t.Errorf("%s: status = %d, want %d (%s)", id, results[id].Status, status, results[id].Error)
+				}
+			}
+			for id, message := range tt.error {
+				if !strings.Contains(results[id].Error, message) {
+					t.Errorf("%s: error = %q, want it to contain %q", id, results[id].Error, message)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		item string // item "b", run after item "me" of GET /me
+		want string // JSON body of "b"
+	}{
+		{
+			name: "path",
+			item: `{"id": "b", "path": "/users/{{me.body.id}}"}`,
+			want: `{"id":"7","token":""}`,
+		},
+		{
+			name: "header",
+			item: `{"id": "b", "path": "/users/1", "headers": {"X-Token": "{{me.headers.X-Token}}"}}`,
+			want: `{"id":"1","token":"secret"}`,
+		},
+		{
+			name: "body value keeps its type",
+			item: `{"id": "b", "method": "POST", "path": "/echo", "body": {"owner": "{{me.body.id}}", "tags": "{{me.body.tags}}"}}`,
+			want: `{"owner":7,"tags":["x","y"]}`,
+		},
+		{
+			name: "body text",
+			item: `{"id": "b", "method": "POST", "path": "/echo", "body": {"note": "user {{me.body.name}} ({{me.status}})"}}`,
+			want: `{"note":"user ada (200)"}`,
+		},
+		{
+			name: "array element",
+			item: `{"id": "b", "path": "/users/{{me.body.tags.1}}"}`,
+			want: `{"id":"y","token":""}`,
+		},
+		{
+			name: "reference with spaces",
+			item: `{"id": "b", "method": "POST", "path": "/echo", "body": {"ref": "{{ me.body.name }}"}}`,
+			want: `{"ref":"ada"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := runBatch(t, batchRouter(), `[{"id": "me", "path": "/me"}, `+tt.item+`]`)
+			result := results["b"]
+			if result.Status != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (%s)", result.Status, result.Error)
+			}
+
+			var got, want any
+			if err := json.Unmarshal(result.Body, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("body = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestBatchReferenceErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  string
+		error string
+	}{
+		{"missing element", `{"id": "b", "path": "/users/{{me.body.tags.5}}"}`, "no element 5"},
+		{"field of a scalar", `{"id": "b", "path": "/users/{{me.body.id.x}}"}`, "no field x"},
+		{"invalid reference", `{"id": "b", "path": "/users/{{me.cookies}}"}`, "invalid reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := runBatch(t, batchRouter(), `[{"id": "me", "path": "/me"}, `+tt.item+`]`)
+			if results["b"].Status != http.StatusBadRequest || !strings.Contains(results["b"].Error, tt.error) {
+				t.Errorf("result = %d %q, want 400 containing %q", results["b"].Status, results["b"].Error, tt.error)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package router
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/validator"
+)
+
+// Struct tags read by binding. The body is decoded with `json` or `form`
+// tags depending on its content type; `path`, `query` and `header` tags are
+// filled from route params, the query string and request headers. Rules in
+// `binding` tags are validated after decoding.
+//
+//	type UpdatePostRequest struct {
+//		ID      uint   `path:"id" binding:"required"`
+//		OrgID   string `header:"Base-Orgid"`
+//		Notify  bool   `query:"notify"`
+//		Title   string `json:"title" form:"title" binding:"required,max=255"`
+//	}
+const (
+	tagForm   = "form"
+	tagPath   = "path"
+	tagQuery  = "query"
+	tagHeader = "header"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	timeType        = reflect.TypeOf(time.Time{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindRequest decodes the body (by content type), route params, query string
+// and headers into obj
+func (c *Context) bindRequest(obj any) error {
+	if err := c.bindBody(obj); err != nil {
+		return err
+	}
+	return c.bindParts(obj)
+}
+
+// bindBody decodes the request body according to its content type. Requests
+// without a body are left untouched.
+func (c *Context) bindBody(obj any) error {
+	if c.Request.Body == nil || c.Request.ContentLength == 0 && c.ContentType() == "" {
+		return nil
+	}
+
+	contentType := c.ContentType()
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		return c.decodeJSON(obj)
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		if err := c.Request.ParseForm(); err != nil {
+			return err
+		}
+		return bindValues(obj, tagForm, c.Request.PostForm, nil)
+	case strings.Contains(contentType, "multipart/form-data"):
+		form, err := c.MultipartForm()
+		if err != nil {
+			return err
+		}
+		return bindValues(obj, tagForm, form.Value, form.File)
+	default:
+		return fmt.Errorf("unsupported content type: %s", contentType)
+	}
+}
+
+// bindParts fills the fields tagged with path, query and header
+func (c *Context) bindParts(obj any) error {
+	params := make(map[string][]string, len(c.params))
+	for _, param := range c.params {
+		params[param.Key] = []string{param.Value}
+	}
+	if err := bindValues(obj, tagPath, params, nil); err != nil {
+		return err
+	}
+	if err := bindValues(obj, tagQuery, c.Request.URL.Query(), nil); err != nil {
+		return err
+	}
+	return bindValues(obj, tagHeader, c.Request.Header, nil)
+}
+
+// decodeJSON decodes a JSON body. An empty body decodes to nothing so that
+// validation reports the missing fields; type errors are reported per field.
+func (c *Context) decodeJSON(obj any) error {
+	if c.Request.Body == nil {
+		return fmt.Errorf("request body is nil")
+	}
+
+	err := json.NewDecoder(c.Request.Body).Decode(obj)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return validator.ValidationErrors{{
+			Field:   typeErr.Field,
+			Tag:     "type",
+			Value:   typeErr.Value,
+			Message: fmt.Sprintf("%s must be %s", typeErr.Field, describeKind(typeErr.Type)),
+		}}
+	}
+	return fmt.Errorf("invalid JSON body: %w", err)
+}
+
+// validate runs the binding rules of obj
+func validate(obj any) error {
+	if errs := validator.ValidateBinding(obj); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindValues sets the fields of obj, a pointer to a struct, whose tag names a
+// key of values (or files). Fields of embedded structs are bound as well.
+// Values that cannot be converted are reported as validation errors.
+func bindValues(obj any, tag string, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	ptr := reflect.ValueOf(obj)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding requires a pointer to a struct, got %T", obj)
+	}
+	if len(values) == 0 && len(files) == 0 {
+		return nil
+	}
+
+	var errs validator.ValidationErrors
+	bindStruct(ptr.Elem(), tag, values, files, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindStruct binds the fields of one struct value
+func bindStruct(value reflect.Value, tag string, values map[string][]string, files map[string][]*multipart.FileHeader, errs *validator.ValidationErrors) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := value.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindStruct(fieldValue, tag, values, files, errs)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		switch field.Type {
+		case fileHeaderType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fieldValue.Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		case fileHeadersType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fieldValue.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+
+		raw, ok := lookupValues(values, name, tag == tagHeader)
+		if !ok {
+			continue
+		}
+		if err := setField(fieldValue, raw); err != nil {
+			*errs = append(*errs, validator.ValidationError{
+				Field:   name,
+				Tag:     "type",
+				Value:   strings.Join(raw, ","),
+				Message: fmt.Sprintf("%s must be %s", name, describeKind(field.Type)),
+			})
+		}
+	}
+}
+
+// lookupValues returns the values of a key; header names are canonicalized
+func lookupValues(values map[string][]string, name string, header bool) ([]string, bool) {
+	if header {
+		raw := values[http.CanonicalHeaderKey(name)]
+		return raw, len(raw) > 0
+	}
+	raw, ok := values[name]
+	if !ok {
+		// Support PHP style array keys, e.g. ids[]=1&ids[]=2
+		raw, ok = values[name+"[]"]
+	}
+	return raw, ok && len(raw) > 0
+}
+
+// setField converts raw values into a field; slices take every value, other
+// kinds the first one
+func setField(field reflect.Value, raw []string) error {
+	if field.Type() == reflect.TypeOf([]byte(nil)) {
+		field.SetBytes([]byte(raw[0]))
+		return nil
+	}
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(raw), len(raw))
+		for i, s := range raw {
+			if err := setValue(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, raw[0])
+}
+
+// setValue converts a single string into a value
+func setValue(value reflect.Value, s string) error {
+	if value.Kind() == reflect.Pointer {
+		elem := reflect.New(value.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	switch value.Type() {
+	case timeType:
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshaler) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		if s == "" || s == "on" {
+			value.SetBool(s == "on")
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+	return nil
+}
+
+// parseTime accepts RFC 3339 timestamps, dates and Unix seconds
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// describeKind names the expected type in conversion error messages
+func describeKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return "a valid date or time"
+	case t == durationType:
+		return "a valid duration"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a positive integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a valid " + t.String()
+	}
+}
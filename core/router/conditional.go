@@ -0,0 +1,86 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetETag sets the ETag validator of the response. Bare values are quoted;
+// values already quoted or prefixed with W/ are used as is.
+//
+//	c.SetETag(fmt.Sprintf("%d-%d", media.ID, media.UpdatedAt.Unix()))
+func (c *Context) SetETag(tag string) {
+	if !strings.HasPrefix(tag, `"`) && !strings.HasPrefix(tag, `W/"`) {
+		tag = `"` + tag + `"`
+	}
+	c.SetHeader("ETag", tag)
+}
+
+// SetLastModified sets the Last-Modified validator of the response, e.g. from
+// a model's UpdatedAt
+func (c *Context) SetLastModified(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	c.SetHeader("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// Fresh reports whether the client's cached copy is still valid, comparing
+// If-None-Match with the response's ETag or, without it, If-Modified-Since
+// with Last-Modified. Set the validators first; handlers can then skip
+// loading the body:
+//
+//	c.SetLastModified(post.UpdatedAt)
+//	if c.Fresh() {
+//		return c.NotModified()
+//	}
+func (c *Context) Fresh() bool {
+	method := c.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	header := c.Writer.Header()
+	if match := c.Request.Header.Get("If-None-Match"); match != "" {
+		etag := header.Get("ETag")
+		return etag != "" && etagMatches(match, etag)
+	}
+
+	since := c.Request.Header.Get("If-Modified-Since")
+	lastModified := header.Get("Last-Modified")
+	if since == "" || lastModified == "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(sinceTime)
+}
+
+// NotModified responds with 304 Not Modified
+func (c *Context) NotModified() error {
+	header := c.Writer.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	c.Writer.WriteHeader(http.StatusNotModified)
+	return nil
+}
+
+// etagMatches compares an If-None-Match list with an ETag using the weak
+// comparison of RFC 9110
+func etagMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Param constraints restrict the segments a route param matches, e.g.
+// ":id<uint>" or ":slug<[a-z0-9-]+>". A constraint is either the name of a
+// registered constraint or a regular expression the whole segment must
+// match. Requests whose segment fails the constraint do not match the route
+// and fall through to other routes or 404.
+//
+// Registered constraints:
+//
+//	int      signed integer
+//	uint     unsigned integer
+//	uuid     UUID, e.g. 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+//	alpha    letters
+//	alphanum letters and digits
+//	slug     lowercase letters, digits and dashes
+var (
+	paramConstraints = map[string]func(string) bool{
+		"int": func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		},
+		"uint": func(s string) bool {
+			_, err := strconv.ParseUint(s, 10, 64)
+			return err == nil
+		},
+		"uuid":     regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+		"alpha":    regexp.MustCompile(`^[a-zA-Z]+$`).MatchString,
+		"alphanum": regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString,
+		"slug":     regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`).MatchString,
+	}
+	paramConstraintsMu sync.RWMutex
+)
+
+// paramConstraint checks the value of a route param
+type paramConstraint struct {
+	expr  string
+	match func(string) bool
+}
+
+// RegisterParamConstraint adds a named param constraint, replacing an
+// existing one with the same name. Constraints are resolved when routes are
+// registered, so register them before adding routes that use them.
+func RegisterParamConstraint(name string, match func(value string) bool) {
+	paramConstraintsMu.Lock()
+	defer paramConstraintsMu.Unlock()
+	paramConstraints[name] = match
+}
+
+// compileConstraint resolves a registered constraint or compiles a regular
+// expression anchored to the whole segment
+func compileConstraint(expr string) (*paramConstraint, error) {
+	paramConstraintsMu.RLock()
+	match, ok := paramConstraints[expr]
+	paramConstraintsMu.RUnlock()
+	if ok {
+		return &paramConstraint{expr: expr, match: match}, nil
+	}
+
+	// Params match one segment at a time and never see a slash
+	if strings.Contains(expr, "/") {
+		return nil, fmt.Errorf("constraint %q contains '/', but params match a single path segment; use a catch-all instead", expr)
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("constraint %q is neither registered nor a valid regular expression: %w", expr, err)
+	}
+	return &paramConstraint{expr: expr, match: re.MatchString}, nil
+}
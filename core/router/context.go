@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +23,19 @@ type Context struct {
 	mu       sync.RWMutex
 	index    int8
 	handlers []HandlerFunc
+	router   *Router
+	wrapErr  error // error of the handler behind net/http middleware
+
+	// Limits set by BodyLimit and Timeout, enforced around the handler
+	bodyLimit    int64
+	rawBody      io.ReadCloser // request body behind the body limit
+	limitedBody  io.ReadCloser // reader enforcing the body limit
+	timeout      time.Duration
+	timeoutStart time.Time
+	liftTimeout  func() bool // set while the handler runs under a deadline
+
+	version     string // version of the route serving the request
+	pathVersion string // version segment of the request path
 }
 
 // Param represents a URL parameter
@@ -52,6 +65,15 @@ func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
 	c.keys = make(map[string]any)
 	c.index = -1
 	c.handlers = nil
+	c.wrapErr = nil
+	c.bodyLimit = 0
+	c.rawBody = nil
+	c.limitedBody = nil
+	c.timeout = 0
+	c.timeoutStart = time.Time{}
+	c.liftTimeout = nil
+	c.version = ""
+	c.pathVersion = ""
 }
 
 // Context returns the request's context
@@ -89,6 +111,26 @@ func (c *Context) Param(key string) string {
 	return c.params.Get(key)
 }
 
+// ParamUint returns the URL param as an unsigned integer. Routes that declare
+// the param as ":id<uint>" only match valid values, so the error is reserved
+// for unconstrained params.
+func (c *Context) ParamUint(key string) (uint, error) {
+	value, err := strconv.ParseUint(c.params.Get(key), 10, strconv.IntSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s param: %w", key, err)
+	}
+	return uint(value), nil
+}
+
+// ParamInt returns the URL param as an integer
+func (c *Context) ParamInt(key string) (int, error) {
+	value, err := strconv.Atoi(c.params.Get(key))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s param: %w", key, err)
+	}
+	return value, nil
+}
+
 // Query returns the keyed url query value
 func (c *Context) Query(key string) string {
 	value, _ := c.GetQuery(key)
@@ -189,49 +231,58 @@ func (c *Context) MustGet(key string) any {
 	return value
 }
 
-// Bind binds the request body to a struct
+// Bind binds the request body (JSON, form or multipart, by content type),
+// route params, query string and headers to a struct without validating it.
+// See the tag constants in binding.go.
 func (c *Context) Bind(obj any) error {
-	contentType := c.ContentType()
-	switch {
-	case strings.Contains(contentType, "application/json"):
-		return c.BindJSON(obj)
-	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
-		return c.BindForm(obj)
-	case strings.Contains(contentType, "multipart/form-data"):
-		return c.BindForm(obj)
-	default:
-		return fmt.Errorf("unsupported content type: %s", contentType)
-	}
+	return c.bindRequest(obj)
 }
 
 // BindJSON binds the request body as JSON to a struct
 func (c *Context) BindJSON(obj any) error {
-	if c.Request.Body == nil {
-		return fmt.Errorf("request body is nil")
-	}
-	decoder := json.NewDecoder(c.Request.Body)
-	return decoder.Decode(obj)
+	return c.decodeJSON(obj)
 }
 
-// ShouldBindJSON binds the request body as JSON to a struct with validation
+// ShouldBindJSON binds the request body as JSON to a struct and validates
+// its `binding` tags. Invalid input is reported as validator.ValidationErrors.
 func (c *Context) ShouldBindJSON(obj any) error {
-	return c.BindJSON(obj)
+	if err := c.BindJSON(obj); err != nil {
+		return err
+	}
+	return validate(obj)
 }
 
-// BindQuery binds the query parameters to a struct
+// BindQuery binds the query parameters to the fields tagged with query or
+// form
 func (c *Context) BindQuery(obj any) error {
 	values := c.Request.URL.Query()
-	// This is a simplified version - in production you'd use a proper binding library
-	// or implement reflection-based binding
-	return bindData(obj, values)
+	if err := bindValues(obj, tagQuery, values, nil); err != nil {
+		return err
+	}
+	return bindValues(obj, tagForm, values, nil)
 }
 
-// BindForm binds the form data to a struct
+// ShouldBindQuery binds the query parameters to a struct and validates it
+func (c *Context) ShouldBindQuery(obj any) error {
+	if err := c.BindQuery(obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// BindForm binds the form data, including uploaded files, to a struct
 func (c *Context) BindForm(obj any) error {
+	if strings.Contains(c.ContentType(), "multipart/form-data") {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return err
+		}
+		return bindValues(obj, tagForm, form.Value, form.File)
+	}
 	if err := c.Request.ParseForm(); err != nil {
 		return err
 	}
-	return bindData(obj, c.Request.Form)
+	return bindValues(obj, tagForm, c.Request.Form, nil)
 }
 
 // JSON sends a JSON response
@@ -397,9 +448,13 @@ func (c *Context) GetHeader(key string) string {
 	return c.Header(key)
 }
 
-// ShouldBind binds the request body to a struct (alias for Bind)
+// ShouldBind binds the request like Bind and validates the struct's
+// `binding` tags. Invalid input is reported as validator.ValidationErrors.
 func (c *Context) ShouldBind(obj any) error {
-	return c.Bind(obj)
+	if err := c.Bind(obj); err != nil {
+		return err
+	}
+	return validate(obj)
 }
 
 // AbortWithStatusJSON aborts the chain and sends a JSON response with status code
@@ -407,13 +462,3 @@ func (c *Context) AbortWithStatusJSON(code int, obj any) {
 	c.Abort()
 	c.JSON(code, obj)
 }
-
-// bindData is a simplified form/query binding helper
-func bindData(obj any, values url.Values) error {
-	// This is a placeholder - in production, you'd use reflection
-	// to properly bind form values to struct fields
-	// For now, returning nil to avoid compilation errors
-	_ = obj    // Avoid unused parameter warning
-	_ = values // Avoid unused parameter warning
-	return nil
-}
@@ -0,0 +1,363 @@
+package router
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultEncoders returns the encoders registered out of the box. XML and CSV
+// mirror the JSON representation, so field names follow the json tags.
+func defaultEncoders() []Encoder {
+	return []Encoder{
+		{
+			Format:     "json",
+			MediaTypes: []string{"application/json"},
+			Encode:     encodeJSON,
+		},
+		{
+			Format:      "xml",
+			MediaTypes:  []string{"application/xml", "text/xml"},
+			ContentType: "application/xml; charset=utf-8",
+			Encode:      encodeXML,
+		},
+		{
+			Format:      "csv",
+			MediaTypes:  []string{"text/csv"},
+			ContentType: "text/csv; charset=utf-8",
+			Encode:      encodeCSV,
+		},
+		{
+			Format:     "ndjson",
+			MediaTypes: []string{"application/x-ndjson", "application/ndjson", "application/jsonl"},
+			Encode:     encodeNDJSON,
+		},
+	}
+}
+
+// encodeJSON writes data as JSON, like Context.JSON
+func encodeJSON(w io.Writer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// encodeNDJSON writes one JSON document per line: one per element of a slice,
+// or a single one for other values
+func encodeNDJSON(w io.Writer, data any) error {
+	if page, ok := paginated(data); ok {
+		data = page.Data
+	}
+
+	enc := json.NewEncoder(w)
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < value.Len(); i++ {
+			if err := enc.Encode(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return enc.Encode(data)
+}
+
+// encodeXML writes the JSON representation of data as XML below a
+// <response> element; array items are <item> elements
+func encodeXML(w io.Writer, data any) error {
+	value, err := toOrdered(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLValue(enc, "response", value); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// writeXMLValue writes a value as an element
+func writeXMLValue(enc *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case orderedObject:
+		for _, field := range v {
+			if err := writeXMLValue(enc, field.key, field.value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := writeXMLValue(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(scalarString(v))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName turns a JSON key into a valid element name
+func xmlName(key string) string {
+	if key == "" {
+		return "item"
+	}
+	name := []rune(key)
+	for i, r := range name {
+		valid := unicode.IsLetter(r) || r == '_' ||
+			(i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'))
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// encodeCSV writes a slice as CSV with a header row. Objects are flattened
+// with dotted column names, e.g. "role.name"; nested arrays are written as
+// JSON. The columns are those of the first record followed by columns that
+// only later records have.
+func encodeCSV(w io.Writer, data any) error {
+	if page, ok := paginated(data); ok {
+		data = page.Data
+	}
+
+	value, err := toOrdered(data)
+	if err != nil {
+		return err
+	}
+
+	var rows []any
+	switch v := value.(type) {
+	case []any:
+		rows = v
+	case nil:
+	default:
+		rows = []any{v}
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	records := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		record := make(map[string]string)
+		if err := flattenCSV(record, "", row); err != nil {
+			return err
+		}
+		if _, ok := row.(orderedObject); !ok {
+			// Rows of scalars or arrays form a single column
+			record = map[string]string{"value": record[""]}
+		}
+		for _, column := range csvColumns(row) {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+		records[i] = record
+	}
+
+	cw := csv.NewWriter(w)
+	if len(columns) > 0 {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	line := make([]string, len(columns))
+	for _, record := range records {
+		for i, column := range columns {
+			line[i] = record[column]
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// flattenCSV adds the cells of a value to a record
+func flattenCSV(record map[string]string, prefix string, value any) error {
+	switch v := value.(type) {
+	case orderedObject:
+		for _, field := range v {
+			key := field.key
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flattenCSV(record, key, field.value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		record[prefix] = string(b)
+	case string:
+		record[prefix] = escapeFormula(v)
+	default:
+		record[prefix] = scalarString(v)
+	}
+	return nil
+}
+
+// csvColumns returns the columns of a row in order
+func csvColumns(row any) []string {
+	object, ok := row.(orderedObject)
+	if !ok {
+		return []string{"value"}
+	}
+	var columns []string
+	var collect func(prefix string, object orderedObject)
+	collect = func(prefix string, object orderedObject) {
+		for _, field := range object {
+			key := field.key
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if nested, ok := field.value.(orderedObject); ok {
+				collect(key, nested)
+				continue
+			}
+			columns = append(columns, key)
+		}
+	}
+	collect("", object)
+	return columns
+}
+
+// escapeFormula prevents spreadsheet applications from evaluating cells that
+// start like a formula
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// scalarString renders a JSON scalar
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// orderedObject is a JSON object that keeps the order of its keys
+type orderedObject []orderedField
+
+type orderedField struct {
+	key   string
+	value any
+}
+
+// MarshalJSON writes the object with its keys in order
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toOrdered converts data to its JSON representation: orderedObject, []any,
+// string, json.Number, bool or nil
+func toOrdered(data any) (any, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+// decodeOrdered decodes the next JSON value, keeping the order of object keys
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		object := orderedObject{}
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, orderedField{key: keyToken.(string), value: value})
+		}
+		_, err = dec.Token() // '}'
+		return object, err
+	case '[':
+		array := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token() // ']'
+		return array, err
+	default:
+		return nil, fmt.Errorf("unexpected JSON delimiter %q", delim)
+	}
+}
@@ -0,0 +1,244 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Host is a virtual host: requests whose Host header matches its pattern are
+// served by its own routes, middleware and NotFound handler. Requests for
+// other hosts go to the routes registered on the Router, the default host.
+//
+//	admin := r.Host("admin.example.com")
+//	admin.Use(middleware.Recovery(log))
+//	admin.GET("/", dashboard)
+//
+//	tenants := r.Host(":tenant.example.com")
+//	tenants.GET("/", func(c *router.Context) error {
+//		return c.String(http.StatusOK, c.Param("tenant"))
+//	})
+//
+// A host's middleware stack is separate from Router.Use; add what its routes
+// need before registering them. Without a NotFound handler of its own, the
+// Router's is used.
+type Host struct {
+	routeSet
+	router  *Router
+	pattern string
+	labels  []hostLabel
+	params  int
+	order   int
+}
+
+// hostLabel is one dot-separated label of a host pattern
+type hostLabel struct {
+	value      string // literal label, or the param name
+	param      bool
+	constraint *paramConstraint
+}
+
+// Host returns the virtual host for a pattern, creating it on first use.
+// Patterns are host names without a port; a label may be a param such as
+// ":tenant", optionally constrained like path params (":id<int>"), whose
+// value is available through Context.Param. Hosts without params are matched
+// before hosts with fewer params, which are matched before hosts with more.
+func (r *Router) Host(pattern string) *Host {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hostByPattern(pattern)
+}
+
+// hostByPattern returns or creates a host; r.mu must be held
+func (r *Router) hostByPattern(pattern string) *Host {
+	pattern = normalizeHost(pattern)
+	for _, h := range r.hosts {
+		if h.pattern == pattern {
+			return h
+		}
+	}
+
+	labels, params, err := parseHostPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	h := &Host{
+		routeSet: routeSet{trees: make(map[string]*node)},
+		router:   r,
+		pattern:  pattern,
+		labels:   labels,
+		params:   params,
+		order:    len(r.hosts),
+	}
+	r.hosts = append(r.hosts, h)
+	sort.SliceStable(r.hosts, func(i, j int) bool {
+		if r.hosts[i].params != r.hosts[j].params {
+			return r.hosts[i].params < r.hosts[j].params
+		}
+		return r.hosts[i].order < r.hosts[j].order
+	})
+	return h
+}
+
+// parseHostPattern splits a host pattern into labels
+func parseHostPattern(pattern string) ([]hostLabel, int, error) {
+	if pattern == "" {
+		return nil, 0, fmt.Errorf("router: empty host pattern")
+	}
+
+	var (
+		labels []hostLabel
+		params int
+	)
+	for _, label := range strings.Split(pattern, ".") {
+		if label == "" {
+			return nil, 0, fmt.Errorf("router: invalid host pattern %q", pattern)
+		}
+		if label[0] != ':' {
+			labels = append(labels, hostLabel{value: label})
+			continue
+		}
+
+		name, expr := splitWildcard(label)
+		if name == "" {
+			return nil, 0, fmt.Errorf("router: host param without a name in %q", pattern)
+		}
+		var constraint *paramConstraint
+		if expr != "" {
+			c, err := compileConstraint(expr)
+			if err != nil {
+				return nil, 0, fmt.Errorf("router: host %q: %w", pattern, err)
+			}
+			constraint = c
+		}
+		labels = append(labels, hostLabel{value: name, param: true, constraint: constraint})
+		params++
+	}
+	return labels, params, nil
+}
+
+// matchHost finds the host a request is addressed to and its params,
+// defaulting to the Router's own routes
+func (r *Router) matchHost(requestHost string) (*routeSet, Params) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.hosts) == 0 {
+		return &r.routeSet, nil
+	}
+
+	name := requestHost
+	if host, _, err := net.SplitHostPort(requestHost); err == nil {
+		name = host
+	}
+	name = normalizeHost(name)
+	labels := strings.Split(name, ".")
+
+	for _, h := range r.hosts {
+		if params, ok := h.match(labels); ok {
+			return &h.routeSet, params
+		}
+	}
+	return &r.routeSet, nil
+}
+
+// match matches the labels of a request host
+func (h *Host) match(labels []string) (Params, bool) {
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+
+	var params Params
+	for i, label := range h.labels {
+		if !label.param {
+			if labels[i] != label.value {
+				return nil, false
+			}
+			continue
+		}
+		if label.constraint != nil && !label.constraint.match(labels[i]) {
+			return nil, false
+		}
+		params = append(params, Param{Key: label.value, Value: labels[i]})
+	}
+	return params, true
+}
+
+// normalizeHost lowercases a host name and drops a trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// Pattern returns the host pattern
+func (h *Host) Pattern() string {
+	return h.pattern
+}
+
+// Use adds middleware to the host
+func (h *Host) Use(middleware ...MiddlewareFunc) {
+	h.router.mu.Lock()
+	defer h.router.mu.Unlock()
+	h.middleware = append(h.middleware, middleware...)
+}
+
+// NotFound sets the 404 handler of the host
+func (h *Host) NotFound(handler HandlerFunc) {
+	h.router.mu.Lock()
+	defer h.router.mu.Unlock()
+	h.notFound = handler
+}
+
+// MethodNotAllowed sets the 405 handler of the host
+func (h *Host) MethodNotAllowed(handler HandlerFunc) {
+	h.router.mu.Lock()
+	defer h.router.mu.Unlock()
+	h.methodNotAllowed = handler
+}
+
+// Group creates a route group on the host
+func (h *Host) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup {
+	return &RouterGroup{
+		router:     h.router,
+		prefix:     prefix,
+		middleware: middleware,
+		host:       h.pattern,
+	}
+}
+
+// GET registers a GET route on the host
+func (h *Host) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return h.Handle(http.MethodGet, path, handler, middleware...)
+}
+
+// POST registers a POST route on the host
+func (h *Host) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return h.Handle(http.MethodPost, path, handler, middleware...)
+}
+
+// PUT registers a PUT route on the host
+func (h *Host) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return h.Handle(http.MethodPut, path, handler, middleware...)
+}
+
+// DELETE registers a DELETE route on the host
+func (h *Host) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return h.Handle(http.MethodDelete, path, handler, middleware...)
+}
+
+// PATCH registers a PATCH route on the host
+func (h *Host) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return h.Handle(http.MethodPatch, path, handler, middleware...)
+}
+
+// Handle registers a route on the host
+func (h *Host) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return h.router.handle(&Route{Method: method, Path: path, Host: h.pattern}, handler, middleware)
+}
+
+// Mount serves every request below prefix on the host with a net/http
+// handler, like Router.Mount
+func (h *Host) Mount(prefix string, handler http.Handler, middleware ...MiddlewareFunc) {
+	h.router.mount(h.pattern, "", "", prefix, handler, middleware)
+}
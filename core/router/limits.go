@@ -0,0 +1,328 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BodyLimit limits the size of request bodies to n bytes. Larger requests
+// are answered with 413 Request Entity Too Large; bodies without a
+// Content-Length fail to read past the limit with an *http.MaxBytesError,
+// also when middleware after BodyLimit reads them. The limit closest to the
+// handler wins, so a route can raise or lower the limit of its group:
+//
+//	api := r.Group("/api", router.BodyLimit(1<<20))
+//	api.POST("/uploads", upload, router.BodyLimit(50<<20))
+func BodyLimit(n int64) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.setBodyLimit(n)
+			return next(c)
+		}
+	}
+}
+
+// Timeout gives the handler a deadline of d after the first Timeout
+// middleware of the route ran. The deadline is set on Context.Context(), so
+// GORM queries and storage calls made with it are cancelled. If the handler
+// has not started the response when the deadline passes, the client gets
+// 504 Gateway Timeout; a response already under way is aborted instead of
+// being left half-written. Like BodyLimit, the timeout closest to the
+// handler wins, and Timeout(0) removes it from a route:
+//
+//	r.Use(router.Timeout(30 * time.Second))
+//	r.POST("/reports", generate, router.Timeout(5*time.Minute))
+//
+// Handlers that start an event stream with Context.SSE or hijack the
+// connection, e.g. to upgrade it to a WebSocket, lift the deadline.
+func Timeout(d time.Duration) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.timeout = d
+			if c.timeoutStart.IsZero() {
+				c.timeoutStart = time.Now()
+			}
+			return next(c)
+		}
+	}
+}
+
+// setBodyLimit caps reads of the request body at n bytes. A body still
+// read through an earlier limit is capped again from its source, so that a
+// later limit can also raise it.
+func (c *Context) setBodyLimit(n int64) {
+	c.bodyLimit = n
+	body := c.Request.Body
+	if body == nil || body == http.NoBody {
+		return
+	}
+	if c.limitedBody != nil && body == c.limitedBody {
+		body = c.rawBody
+	} else {
+		c.rawBody = body
+	}
+	c.limitedBody = http.MaxBytesReader(c.Writer, body, n)
+	c.Request.Body = c.limitedBody
+}
+
+// enforceLimits applies the body limit and timeout set by the route's
+// middleware to its handler
+func enforceLimits(handler HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.bodyLimit > 0 && c.Request.ContentLength > c.bodyLimit {
+			return writeBodyTooLarge(c, c.bodyLimit)
+		}
+
+		if c.timeout <= 0 {
+			return handler(c)
+		}
+		return runWithTimeout(c, handler)
+	}
+}
+
+// writeBodyTooLarge answers a request whose body exceeds the limit
+func writeBodyTooLarge(c *Context, limit int64) error {
+	return c.JSON(http.StatusRequestEntityTooLarge, typedError{
+		Error: "request body exceeds " + strconv.FormatInt(limit, 10) + " bytes",
+	})
+}
+
+// runWithTimeout runs the handler under the route's deadline. The handler
+// runs in its own goroutine so that the client can be answered when the
+// deadline passes; the Context is pooled, so this still waits for the
+// handler to return.
+func runWithTimeout(c *Context, handler HandlerFunc) error {
+	ctx, cancel := context.WithDeadline(c.Request.Context(), c.timeoutStart.Add(c.timeout))
+	defer cancel()
+
+	request, writer := c.Request, c.Writer
+	tw := &timeoutWriter{ResponseWriter: writer, header: writer.Header().Clone()}
+	c.Request, c.Writer = request.WithContext(ctx), tw
+	c.liftTimeout = func() bool {
+		if !tw.lift() {
+			return false
+		}
+		c.Request = c.Request.WithContext(request.Context())
+		return true
+	}
+	defer func() {
+		c.Request, c.Writer = request, writer
+		c.liftTimeout = nil
+	}()
+
+	done := make(chan error, 1)
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		done <- handler(c)
+	}()
+
+	select {
+	case err := <-done:
+		tw.finish()
+		return err
+	case p := <-panicked:
+		tw.finish()
+		panic(p)
+	case <-ctx.Done():
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// The client went away; there is nobody left to answer
+		select {
+		case err := <-done:
+			return err
+		case p := <-panicked:
+			panic(p)
+		}
+	}
+
+	answered, lifted := tw.timeout()
+	if lifted {
+		// The handler streams or took over the connection; it has no deadline
+		select {
+		case err := <-done:
+			return err
+		case p := <-panicked:
+			panic(p)
+		}
+	}
+	select {
+	case <-done:
+	case <-panicked:
+		// The client has its answer; the handler failed past the deadline
+	}
+	if !answered {
+		// The response had started: abort the connection rather than end
+		// it as if it was complete
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+// timeoutWriter passes a handler's response through until its deadline
+// passes, after which writes fail with http.ErrHandlerTimeout. The handler
+// gets its own header map, which is copied when the response starts, so
+// that the timeout response does not race with it.
+type timeoutWriter struct {
+	ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	timedOut bool
+	lifted   bool // the deadline no longer applies
+}
+
+// Header returns the handler's header map
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader sends the handler's headers and status code
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.writeHeader(code)
+}
+
+// writeHeader copies the headers on the first call; w.mu must be held
+func (w *timeoutWriter) writeHeader(code int) {
+	if !w.ResponseWriter.Written() {
+		copyHeader(w.ResponseWriter.Header(), w.header)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the data unless the deadline has passed
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !w.ResponseWriter.Written() {
+		w.writeHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush flushes the response unless the deadline has passed
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	if !w.ResponseWriter.Written() {
+		w.writeHeader(http.StatusOK)
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Status returns the response status code
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Status()
+}
+
+// Size returns the size of the response body
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Size()
+}
+
+// Written returns true if the response has been written
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Written()
+}
+
+// Hijack implements the http.Hijacker interface
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	// A hijacked connection is no longer a response the deadline can answer
+	w.lifted = true
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap returns the underlying writer, e.g. for http.ResponseController
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// lift removes the deadline from the response. It reports false if the
+// deadline has already passed.
+func (w *timeoutWriter) lift() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return false
+	}
+	w.lifted = true
+	return true
+}
+
+// timeout stops the handler's writes and answers 504 Gateway Timeout,
+// unless the deadline was lifted. It reports false if the response had
+// already started.
+func (w *timeoutWriter) timeout() (answered, lifted bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lifted {
+		return false, true
+	}
+	w.timedOut = true
+	if w.ResponseWriter.Written() {
+		return false, false
+	}
+
+	body, _ := json.Marshal(typedError{Error: "request timed out"})
+	header := w.ResponseWriter.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	w.ResponseWriter.Write(body)
+	return true, false
+}
+
+// finish hands the headers of a handler that returned without writing back
+// to the outer middleware, which may still respond
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.ResponseWriter.Written() {
+		copyHeader(w.ResponseWriter.Header(), w.header)
+	}
+}
+
+// copyHeader replaces the fields of dst with those of src
+func copyHeader(dst, src http.Header) {
+	for key := range dst {
+		if _, ok := src[key]; !ok {
+			dst.Del(key)
+		}
+	}
+	for key, values := range src {
+		dst[key] = values
+	}
+}
@@ -0,0 +1,193 @@
+package router
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	const deadline = 50 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		status  int
+		body    string // substring of the response body
+		header  string // Content-Type of the response
+		panics  any
+	}{
+		{
+			name: "handler finishes in time",
+			handler: func(c *Context) error {
+				c.SetHeader("X-Handler", "yes")
+				return c.String(http.StatusCreated, "done")
+			},
+			status: http.StatusCreated,
+			body:   "done",
+		},
+		{
+			name: "deadline before the first write",
+			handler: func(c *Context) error {
+				<-c.Context().Done()
+				// Let the client be answered first
+				time.Sleep(deadline)
+				if err := c.String(http.StatusOK, "late"); !errors.Is(err, http.ErrHandlerTimeout) {
+					t.Errorf("write after the deadline = %v, want %v", err, http.ErrHandlerTimeout)
+				}
+				return c.Context().Err()
+			},
+			status: http.StatusGatewayTimeout,
+			body:   "request timed out",
+			header: "application/json",
+		},
+		{
+			name: "deadline after the first write",
+			handler: func(c *Context) error {
+				c.Writer.WriteHeader(http.StatusOK)
+				c.Writer.Write([]byte("partial"))
+				c.Writer.Flush()
+				<-c.Context().Done()
+				time.Sleep(deadline)
+				return nil
+			},
+			status: http.StatusOK,
+			body:   "partial",
+			panics: http.ErrAbortHandler,
+		},
+		{
+			name: "event stream lifts the deadline",
+			handler: func(c *Context) error {
+				stream, err := c.SSE()
+				if err != nil {
+					return err
+				}
+				time.Sleep(3 * deadline)
+				if err := c.Context().Err(); err != nil {
+					t.Errorf("stream context = %v, want no deadline", err)
+				}
+				return stream.Send(SSEvent{Event: "tick", Data: "late"})
+			},
+			status: http.StatusOK,
+			body:   "event: tick",
+			header: "text/event-stream",
+		},
+		{
+			name: "panic before the deadline",
+			handler: func(c *Context) error {
+				panic("boom")
+			},
+			status: http.StatusOK,
+			panics: "boom",
+		},
+		{
+			name: "panic after the deadline",
+			handler: func(c *Context) error {
+				<-c.Context().Done()
+				time.Sleep(deadline)
+				panic("boom")
+			},
+			status: http.StatusGatewayTimeout,
+			body:   "request timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.GET("/slow", tt.handler, Timeout(deadline))
+
+			rec := httptest.NewRecorder()
+			func() {
+				defer func() {
+					if p := recover(); p != tt.panics {
+						t.Errorf("panic = %v, want %v", p, tt.panics)
+					}
+				}()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+			}()
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.body)
+			}
+			if tt.header != "" && !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.header) {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.header)
+			}
+		})
+	}
+}
+
+func TestRunWithTimeoutHijack(t *testing.T) {
+	const deadline = 50 * time.Millisecond
+
+	r := New()
+	r.GET("/upgrade", func(c *Context) error {
+		conn, rw, err := http.NewResponseController(c.Writer).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		time.Sleep(3 * deadline)
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		return rw.Flush()
+	}, Timeout(deadline))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(bufio.NewReader(resp.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "hijacked" {
+		t.Errorf("response = %d %q, want 200 %q", resp.StatusCode, body, "hijacked")
+	}
+}
+
+func TestTimeoutClosestToHandlerWins(t *testing.T) {
+	tests := []struct {
+		name   string
+		route  time.Duration
+		status int
+	}{
+		{"shorter route timeout", 20 * time.Millisecond, http.StatusGatewayTimeout},
+		{"longer route timeout", time.Second, http.StatusOK},
+		{"timeout removed", 0, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Use(Timeout(50 * time.Millisecond))
+			r.GET("/work", func(c *Context) error {
+				select {
+				case <-time.After(100 * time.Millisecond):
+					return c.String(http.StatusOK, "done")
+				case <-c.Context().Done():
+					return c.Context().Err()
+				}
+			}, Timeout(tt.route))
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/work", nil))
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
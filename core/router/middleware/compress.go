@@ -0,0 +1,304 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"base/core/router"
+)
+
+// CompressConfig contains compression middleware configuration
+type CompressConfig struct {
+	// Level is the gzip/deflate compression level
+	Level int
+
+	// MinLength is the body size below which responses are sent uncompressed
+	MinLength int
+
+	// ContentTypes lists the compressible media types; entries ending in "/"
+	// match a whole type, e.g. "text/"
+	ContentTypes []string
+
+	// SkipPaths lists paths that are never compressed
+	SkipPaths []string
+}
+
+// DefaultCompressConfig returns default compression configuration
+func DefaultCompressConfig() *CompressConfig {
+	return &CompressConfig{
+		Level:     gzip.DefaultCompression,
+		MinLength: 1024,
+		ContentTypes: []string{
+			"text/",
+			"application/json",
+			"application/javascript",
+			"application/xml",
+			"application/x-ndjson",
+			"application/ndjson",
+			"image/svg+xml",
+		},
+	}
+}
+
+// Compress creates middleware that compresses responses with gzip or deflate,
+// as negotiated with Accept-Encoding. Bodies smaller than MinLength, media
+// types outside ContentTypes, event streams, partial content and responses
+// that already have a Content-Encoding are sent as is.
+func Compress(config *CompressConfig) router.MiddlewareFunc {
+	if config == nil {
+		config = DefaultCompressConfig()
+	}
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, config.Level)
+			return w
+		}},
+		"deflate": {New: func() any {
+			w, _ := zlib.NewWriterLevel(io.Discard, config.Level)
+			return w
+		}},
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			for _, path := range config.SkipPaths {
+				if c.Request.URL.Path == path {
+					return next(c)
+				}
+			}
+
+			// Vary even when not compressing so caches keep the variants apart
+			router.AddVary(c.Writer.Header(), "Accept-Encoding")
+
+			encoding := negotiateEncoding(c.Header("Accept-Encoding"))
+			if encoding == "" || c.IsWebSocket() {
+				return next(c)
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: c.Writer,
+				config:         config,
+				encoding:       encoding,
+				pool:           pools[encoding],
+				status:         http.StatusOK,
+			}
+			c.Writer = cw
+			defer func() {
+				c.Writer = cw.ResponseWriter
+			}()
+
+			err := next(c)
+			if closeErr := cw.close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip on equal quality
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, coding := range []string{"gzip", "deflate"} {
+		if q := router.EncodingQuality(header, coding); q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressor is implemented by the pooled gzip and zlib writers
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressWriter buffers the start of a response until it is known whether
+// it is worth compressing
+type compressWriter struct {
+	router.ResponseWriter
+	config   *CompressConfig
+	encoding string
+	pool     *sync.Pool
+
+	status     int
+	written    bool // WriteHeader was called
+	decided    bool // the header went out, compressed or not
+	buf        bytes.Buffer
+	size       int
+	compressor compressor
+}
+
+// WriteHeader records the status; the header is sent once the body is known
+func (w *compressWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	w.status = code
+	w.written = true
+}
+
+// Write buffers data until MinLength is reached, then compresses it
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.size += len(data)
+
+	if !w.decided {
+		w.buf.Write(data)
+		if w.buf.Len() < w.config.MinLength {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends the buffered data. Streamed responses are decided on the first
+// flush, so they are only compressed if MinLength was buffered by then.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if !w.written {
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+	if w.compressor != nil {
+		w.compressor.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Status returns the response status code
+func (w *compressWriter) Status() int {
+	return w.status
+}
+
+// Size returns the uncompressed size of the response body
+func (w *compressWriter) Size() int {
+	return w.size
+}
+
+// Written returns true if the response has been written
+func (w *compressWriter) Written() bool {
+	return w.written
+}
+
+// Hijack implements the http.Hijacker interface
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap returns the underlying writer, e.g. for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide sends the header, compressed if the response qualifies, followed by
+// the buffered data
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	if w.shouldCompress() {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// Strong validators describe the uncompressed bytes
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.ResponseWriter.WriteHeader(w.status)
+
+		w.compressor = w.pool.Get().(compressor)
+		w.compressor.Reset(w.ResponseWriter)
+	} else {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.compressor != nil {
+		_, err = w.compressor.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// shouldCompress checks the status, headers and buffered size
+func (w *compressWriter) shouldCompress() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if w.buf.Len() < w.config.MinLength {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf.Bytes())
+		header.Set("Content-Type", contentType)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+	for _, compressible := range w.config.ContentTypes {
+		if mediaType == compressible || strings.HasSuffix(compressible, "/") && strings.HasPrefix(mediaType, compressible) {
+			return true
+		}
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// close sends a response that never reached MinLength and finishes the
+// compressed stream
+func (w *compressWriter) close() error {
+	if !w.written {
+		// Nothing was written; the router's defaults apply
+		return nil
+	}
+	if !w.decided {
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.compressor == nil {
+		return nil
+	}
+
+	err := w.compressor.Close()
+	w.compressor.Reset(io.Discard)
+	w.pool.Put(w.compressor)
+	w.compressor = nil
+	return err
+}
@@ -3,177 +3,241 @@ package middleware
 import (
 	"base/core/config"
 	"base/core/router"
-	"strings"
+	"base/core/types"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
 )
 
 // ConfigurableMiddleware creates middleware that can be conditionally applied based on configuration
 type ConfigurableMiddleware struct {
-	config *config.MiddlewareConfig
+	config    *config.MiddlewareConfig
+	validator func(token string) (any, error)
+	limiters  map[string]*TokenBucket // rate limit source -> limiter
+	mu        sync.Mutex
 }
 
 // NewConfigurableMiddleware creates a new configurable middleware instance
 func NewConfigurableMiddleware(cfg *config.MiddlewareConfig) *ConfigurableMiddleware {
 	return &ConfigurableMiddleware{
-		config: cfg,
+		config:    cfg,
+		validator: validateJWT,
+		limiters:  make(map[string]*TokenBucket),
 	}
 }
 
+// SetAuthValidator replaces the token validator used when authentication is
+// required. The default validates the JWTs issued by the authentication module.
+func (cm *ConfigurableMiddleware) SetAuthValidator(validator func(token string) (any, error)) {
+	cm.validator = validator
+}
+
+// validateJWT validates a JWT and returns the user ID it was issued for
+func validateJWT(token string) (any, error) {
+	return types.ValidateJWT(token)
+}
+
 // ConditionalAPIKey returns API key middleware only if required for the path
 func (cm *ConfigurableMiddleware) ConditionalAPIKey() router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
 			path := c.Request.URL.Path
-			
+
 			if cm.config.IsAPIKeyRequired(path) {
 				// Apply API key middleware
 				apiKeyMiddleware := Api()
 				return apiKeyMiddleware(next)(c)
 			}
-			
+
 			// Skip API key middleware
 			return next(c)
 		}
 	}
 }
 
-// ConditionalAuth returns auth middleware only if required for the path
+// ConditionalAuth returns auth middleware only if required for the path.
+// The validated user ID is stored under "user_id".
 func (cm *ConfigurableMiddleware) ConditionalAuth() router.MiddlewareFunc {
+	authConfig := DefaultAuthConfig()
+	authConfig.Key = "user_id"
+	authConfig.TokenValidator = func(token string) (any, error) {
+		return cm.validator(token)
+	}
+	authMiddleware := Auth(authConfig)
+
 	return func(next router.HandlerFunc) router.HandlerFunc {
+		authNext := authMiddleware(next)
 		return func(c *router.Context) error {
-			path := c.Request.URL.Path
-			
-			if cm.config.IsAuthRequired(path) {
-				// Apply auth middleware
-				authConfig := &AuthConfig{
-					HeaderName: "Authorization",
-					Scheme:     "Bearer",
-				}
-				authMiddleware := Auth(authConfig)
-				return authMiddleware(next)(c)
+			if cm.config.IsAuthRequired(c.Request.URL.Path) {
+				return authNext(c)
 			}
-			
+
 			// Skip auth middleware
 			return next(c)
 		}
 	}
 }
 
-// ConditionalRateLimit returns rate limit middleware only if required for the path
+// ConditionalRateLimit returns rate limit middleware only if required for the path.
+// Paths whose limit comes from the same setting share one limiter.
 func (cm *ConfigurableMiddleware) ConditionalRateLimit() router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
-			path := c.Request.URL.Path
-			
-			if cm.config.IsRateLimitRequired(path) {
-				// Determine rate limit settings based on path
-				requests := cm.config.RateLimitRequests
-				window := cm.config.GetRateLimitDuration()
-				
-				// Use webhook settings if it's a webhook path
-				if cm.isWebhookPath(path) {
-					requests = cm.config.WebhookRateLimitRequests
-					window = cm.config.GetWebhookRateLimitDuration()
-				}
-				
-				// Apply rate limit middleware
-				rateLimitConfig := &RateLimitConfig{
-					Limiter: NewTokenBucket(requests, window, requests),
-					KeyFunc: func(c *router.Context) string {
-						return c.ClientIP()
-					},
-					ErrorHandler: func(c *router.Context) error {
-						return c.JSON(429, map[string]string{
-							"error": "Rate limit exceeded",
-						})
-					},
-				}
-				rateLimitMiddleware := RateLimit(rateLimitConfig)
-				return rateLimitMiddleware(next)(c)
+			limit, source := cm.config.RateLimitFor(c.Request.URL.Path)
+			if limit == nil {
+				// Skip rate limit middleware
+				return next(c)
+			}
+
+			key := rateLimitKey(limit.KeyFunc, c)
+			if !cm.limiter(source, limit).Allow(key) {
+				return c.JSON(http.StatusTooManyRequests, map[string]string{
+					"error": "Rate limit exceeded",
+				})
 			}
-			
-			// Skip rate limit middleware
+
 			return next(c)
 		}
 	}
 }
 
+// limiter returns the token bucket for a rate limit, creating it on first use
+func (cm *ConfigurableMiddleware) limiter(source string, limit *config.RateLimitConfig) *TokenBucket {
+	id := fmt.Sprintf("%s|%d|%s", source, limit.Requests, limit.Window)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if limiter, ok := cm.limiters[id]; ok {
+		return limiter
+	}
+
+	window, err := time.ParseDuration(limit.Window)
+	if err != nil || window <= 0 {
+		window = time.Minute
+	}
+	limiter := NewTokenBucket(limit.Requests, window, limit.Requests)
+	cm.limiters[id] = limiter
+	return limiter
+}
+
 // ConditionalLogging returns logging middleware only if required for the path
 func (cm *ConfigurableMiddleware) ConditionalLogging() router.MiddlewareFunc {
 	return func(next router.HandlerFunc) router.HandlerFunc {
 		return func(c *router.Context) error {
 			path := c.Request.URL.Path
-			
+
 			if cm.config.IsLoggingRequired(path) {
 				// Apply logging middleware - this will be handled by main.go
 				// For now, just continue to next middleware
 				return next(c)
 			}
-			
+
 			// Skip logging middleware
 			return next(c)
 		}
 	}
 }
 
-// WebhookSignature creates webhook signature verification middleware
-func (cm *ConfigurableMiddleware) WebhookSignature(provider string) router.MiddlewareFunc {
+// ConditionalCORS returns CORS middleware only if CORS is enabled for the path
+func (cm *ConfigurableMiddleware) ConditionalCORS(allowedOrigins []string) router.MiddlewareFunc {
+	corsMiddleware := CORSMiddleware(allowedOrigins)
+
 	return func(next router.HandlerFunc) router.HandlerFunc {
+		corsNext := corsMiddleware(next)
 		return func(c *router.Context) error {
-			path := c.Request.URL.Path
-			
-			// Only apply to webhook paths if signature verification is enabled
-			if cm.isWebhookPath(path) && cm.config.WebhookSignatureEnabled {
-				// TODO: Implement provider-specific signature verification
-				// For now, just log and continue
-				// This would verify HMAC signatures from Stripe, GitHub, etc.
+			if cm.config.IsCORSEnabled(c.Request.URL.Path) {
+				return corsNext(c)
 			}
-			
 			return next(c)
 		}
 	}
 }
 
-// isWebhookPath checks if a path is configured as a webhook path
-func (cm *ConfigurableMiddleware) isWebhookPath(path string) bool {
-	for _, webhookPath := range cm.config.WebhookPaths {
-		if cm.pathMatches(path, webhookPath) {
-			return true
+// ConditionalWebhookSignature verifies webhook signatures on the paths a
+// module configured signature verification for
+func (cm *ConfigurableMiddleware) ConditionalWebhookSignature() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			signature := cm.config.WebhookSignatureFor(c.Request.URL.Path)
+			if signature == nil {
+				return next(c)
+			}
+			if ok, err := cm.verifyWebhookSignature(c, signature); !ok {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// WebhookSignature creates webhook signature verification middleware for a
+// provider, for use on individual routes. The path's configured signature
+// settings take precedence over the provider defaults.
+func (cm *ConfigurableMiddleware) WebhookSignature(provider string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			// Only verify if signature verification is enabled
+			if !cm.config.WebhookSignatureEnabled {
+				return next(c)
+			}
+
+			signature := cm.config.WebhookSignatureFor(c.Request.URL.Path)
+			if signature == nil {
+				signature = &config.WebhookSignatureConfig{Provider: provider}
+			}
+			if ok, err := cm.verifyWebhookSignature(c, signature); !ok {
+				return err
+			}
+			return next(c)
 		}
 	}
-	return false
 }
 
-// pathMatches checks if a path matches a pattern (supports wildcards)
-func (cm *ConfigurableMiddleware) pathMatches(path, pattern string) bool {
-	if pattern == path {
-		return true
+// verifyWebhookSignature verifies a webhook signature. It reports false
+// after answering 413 for bodies over the size limit or 401 for invalid
+// signatures.
+func (cm *ConfigurableMiddleware) verifyWebhookSignature(c *router.Context, signature *config.WebhookSignatureConfig) (bool, error) {
+	if signature.MaxBodySize <= 0 && cm.config.WebhookMaxBodySize > 0 {
+		settings := *signature
+		settings.MaxBodySize = cm.config.WebhookMaxBodySize
+		signature = &settings
 	}
-	
-	// Handle wildcard patterns
-	if strings.HasSuffix(pattern, "/*") {
-		prefix := strings.TrimSuffix(pattern, "/*")
-		return strings.HasPrefix(path, prefix)
+
+	err := VerifyWebhookSignature(c, signature)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &tooLarge):
+		return false, c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit),
+		})
 	}
-	
-	return false
+	return false, c.JSON(http.StatusUnauthorized, map[string]string{
+		"error": "Invalid webhook signature: " + err.Error(),
+	})
 }
 
 // ApplyConfigurableMiddleware is a helper function to apply all configurable middleware
-func ApplyConfigurableMiddleware(router *router.Router, cfg *config.MiddlewareConfig) {
+func ApplyConfigurableMiddleware(router *router.Router, cfg *config.MiddlewareConfig) *ConfigurableMiddleware {
 	cm := NewConfigurableMiddleware(cfg)
-	
+
 	// Apply middleware in the correct order
 	if cfg.RecoveryEnabled {
 		router.Use(Recovery(nil)) // Recovery should be first
 	}
-	
-	if cfg.CORSEnabled {
-		// CORS middleware will be applied in main.go
-	}
-	
+
+	// CORS middleware is applied in main.go with ConditionalCORS
+
 	// Apply conditional middleware
 	router.Use(cm.ConditionalAPIKey())
+	router.Use(cm.ConditionalWebhookSignature())
 	router.Use(cm.ConditionalAuth())
 	router.Use(cm.ConditionalRateLimit())
 	router.Use(cm.ConditionalLogging())
+
+	return cm
 }
@@ -26,8 +26,8 @@ func CORSMiddleware(allowedOrigins []string) router.MiddlewareFunc {
 			if allowOrigin != "" {
 				c.SetHeader("Access-Control-Allow-Origin", allowOrigin)
 				c.SetHeader("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH, HEAD")
-				c.SetHeader("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Api-Key, Base-Orgid")
-				c.SetHeader("Access-Control-Expose-Headers", "Content-Length, Content-Type")
+				c.SetHeader("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Api-Key, Base-Orgid, Idempotency-Key, Accept-Version")
+				c.SetHeader("Access-Control-Expose-Headers", "Content-Length, Content-Type, X-Total-Count, X-Page, X-Page-Size, X-Total-Pages, Idempotent-Replayed, API-Version, Deprecation, Sunset, Link")
 				c.SetHeader("Access-Control-Allow-Credentials", "true")
 				c.SetHeader("Access-Control-Max-Age", "43200") // 12 hours
 			}
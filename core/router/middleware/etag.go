@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+
+	"base/core/router"
+)
+
+// ConditionalGETConfig contains conditional GET middleware configuration
+type ConditionalGETConfig struct {
+	// MaxBodySize is the largest response buffered to compute an ETag; larger
+	// responses are streamed without one
+	MaxBodySize int
+
+	// SkipPaths lists paths that are never buffered
+	SkipPaths []string
+}
+
+// DefaultConditionalGETConfig returns default conditional GET configuration
+func DefaultConditionalGETConfig() *ConditionalGETConfig {
+	return &ConditionalGETConfig{
+		MaxBodySize: 4 << 20,
+	}
+}
+
+// ConditionalGET creates middleware that answers GET and HEAD requests whose
+// cached copy is still valid with 304 Not Modified. Successful responses are
+// buffered and get a weak ETag computed from the body unless the handler set
+// its own with Context.SetETag; a Last-Modified set with
+// Context.SetLastModified is checked against If-Modified-Since.
+func ConditionalGET(config *ConditionalGETConfig) router.MiddlewareFunc {
+	if config == nil {
+		config = DefaultConditionalGETConfig()
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			method := c.Request.Method
+			if method != http.MethodGet && method != http.MethodHead || c.IsWebSocket() {
+				return next(c)
+			}
+			for _, path := range config.SkipPaths {
+				if c.Request.URL.Path == path {
+					return next(c)
+				}
+			}
+
+			ew := &etagWriter{ResponseWriter: c.Writer, max: config.MaxBodySize, status: http.StatusOK}
+			c.Writer = ew
+			defer func() {
+				c.Writer = ew.ResponseWriter
+			}()
+
+			err := next(c)
+			if ew.streaming || !ew.written {
+				return err
+			}
+			c.Writer = ew.ResponseWriter
+
+			header := c.Writer.Header()
+			if ew.status == http.StatusOK && header.Get("ETag") == "" {
+				sum := sha256.Sum256(ew.buf.Bytes())
+				header.Set("ETag", `W/"`+hex.EncodeToString(sum[:16])+`"`)
+			}
+			if ew.status == http.StatusOK && c.Fresh() {
+				return c.NotModified()
+			}
+
+			c.Writer.WriteHeader(ew.status)
+			if _, writeErr := c.Writer.Write(ew.buf.Bytes()); err == nil {
+				err = writeErr
+			}
+			return err
+		}
+	}
+}
+
+// etagWriter buffers a response until the handler returns, unless it grows
+// past the limit or is flushed
+type etagWriter struct {
+	router.ResponseWriter
+	max       int
+	status    int
+	written   bool
+	streaming bool
+	buf       bytes.Buffer
+	size      int
+}
+
+// WriteHeader records the status until the response is complete
+func (w *etagWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	w.status = code
+	w.written = true
+	if code != http.StatusOK {
+		// Only complete 200 responses are validated
+		w.stream()
+	}
+}
+
+// Write buffers data, switching to streaming past the limit
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.size += len(data)
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.buf.Len()+len(data) > w.max {
+		if err := w.stream(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+// Flush streams the response
+func (w *etagWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.stream() == nil {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// stream sends the header and buffered data and passes later writes through
+func (w *etagWriter) stream() error {
+	if w.streaming {
+		return nil
+	}
+	w.streaming = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// Status returns the response status code
+func (w *etagWriter) Status() int {
+	return w.status
+}
+
+// Size returns the size of the response body
+func (w *etagWriter) Size() int {
+	return w.size
+}
+
+// Written returns true if the response has been written
+func (w *etagWriter) Written() bool {
+	return w.written
+}
+
+// Hijack implements the http.Hijacker interface
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap returns the underlying writer, e.g. for http.ResponseController
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
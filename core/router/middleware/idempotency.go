@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"base/core/router"
+)
+
+// IdempotencyConfig contains idempotency middleware configuration
+type IdempotencyConfig struct {
+	// Store keeps the keys and the responses to replay
+	Store IdempotencyStore
+
+	// Header carries the client's key
+	Header string
+
+	// MaxKeyLength is the longest key accepted
+	MaxKeyLength int
+
+	// MaxBodySize is the largest request body read to fingerprint the
+	// request; larger requests with a key are answered with 413
+	MaxBodySize int64
+
+	// MaxResponseSize is the largest response stored. Larger responses are
+	// sent but not stored, so a retry runs the request again.
+	MaxResponseSize int
+
+	// ScopeFunc returns the namespace of a request's keys. By default keys
+	// belong to the authenticated user. Requests it returns "" for ignore
+	// the key, so stored responses are never replayed to another client.
+	ScopeFunc func(c *router.Context) string
+
+	// SkipPaths lists paths whose requests ignore the key
+	SkipPaths []string
+}
+
+// DefaultIdempotencyConfig returns default idempotency configuration
+func DefaultIdempotencyConfig(store IdempotencyStore) *IdempotencyConfig {
+	return &IdempotencyConfig{
+		Store:           store,
+		Header:          "Idempotency-Key",
+		MaxKeyLength:    191,
+		MaxBodySize:     32 << 20,
+		MaxResponseSize: 1 << 20,
+		ScopeFunc:       idempotencyScope,
+	}
+}
+
+// Idempotency creates middleware that makes unsafe requests carrying an
+// Idempotency-Key header safe to retry. The first request with a key runs
+// and its response is stored; retries with the same key and payload get the
+// stored response, marked with an Idempotent-Replayed header, instead of
+// running again. A retry arriving while the first request still runs gets
+// 409 Conflict, and a key sent with a different method, path or body gets
+// 422 Unprocessable Entity.
+//
+// Responses with a 5xx status are not stored, so such requests can be
+// retried. Place the middleware after authentication: keys are scoped to the
+// user, and requests without one run as if they carried no key.
+func Idempotency(config *IdempotencyConfig) router.MiddlewareFunc {
+	if config == nil || config.Store == nil {
+		panic("middleware: Idempotency needs a store")
+	}
+	if config.ScopeFunc == nil {
+		config.ScopeFunc = idempotencyScope
+	}
+
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c *router.Context) error {
+			key := c.Header(config.Header)
+			if key == "" || safeMethod(c.Request.Method) {
+				return next(c)
+			}
+			for _, path := range config.SkipPaths {
+				if c.Request.URL.Path == path {
+					return next(c)
+				}
+			}
+			scope := config.ScopeFunc(c)
+			if scope == "" {
+				return next(c)
+			}
+			if config.MaxKeyLength > 0 && len(key) > config.MaxKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": fmt.Sprintf("%s must be at most %d characters", config.Header, config.MaxKeyLength),
+				})
+			}
+
+			body, err := readIdempotentBody(c, config.MaxBodySize)
+			if err != nil {
+				return err
+			}
+
+			record, attempt, err := config.Store.Lock(c.Context(), scope, key, requestFingerprint(c, body))
+			switch {
+			case errors.Is(err, ErrIdempotencyInProgress):
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": err.Error(),
+				})
+			case errors.Is(err, ErrIdempotencyKeyReused):
+				return c.JSON(http.StatusUnprocessableEntity, map[string]string{
+					"error": err.Error(),
+				})
+			case err != nil:
+				return err
+			case record != nil:
+				return replayIdempotent(c, record)
+			}
+
+			// The key is held until the response is stored or released;
+			// both must happen even if the request deadline has passed
+			ctx := context.WithoutCancel(c.Context())
+			iw := &idempotencyWriter{ResponseWriter: c.Writer, max: config.MaxResponseSize}
+			c.Writer = iw
+			stored := false
+			defer func() {
+				c.Writer = iw.ResponseWriter
+				if !stored {
+					_ = config.Store.Unlock(ctx, scope, key, attempt)
+				}
+			}()
+
+			err = next(c)
+			if iw.written && iw.status < http.StatusInternalServerError && !iw.overflow {
+				stored = config.Store.Save(ctx, scope, key, attempt, iw.status, iw.header, iw.buf.Bytes()) == nil
+			}
+			return err
+		}
+	}
+}
+
+// idempotencyScope scopes keys to the authenticated user. Unauthenticated
+// requests have no scope: clients sharing an API key cannot be told apart,
+// and one must not receive another's stored response or cookies.
+func idempotencyScope(c *router.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return ""
+}
+
+// safeMethod reports whether a method does not change state
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// readIdempotentBody reads the request body and restores it for the handler
+func readIdempotentBody(c *router.Context, limit int64) ([]byte, error) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	reader := c.Request.Body
+	if limit > 0 {
+		reader = http.MaxBytesReader(c.Writer, reader, limit)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// requestFingerprint identifies a request by its method, URL and body
+func requestFingerprint(c *router.Context, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, c.Request.Method+"\n"+c.Request.URL.Path+"?"+c.Request.URL.RawQuery+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayIdempotent sends a stored response. Headers the current request's
+// middleware has set, e.g. a request ID, are kept.
+func replayIdempotent(c *router.Context, record *IdempotencyRecord) error {
+	header := c.Writer.Header()
+	for name, values := range record.ResponseHeader() {
+		if _, exists := header[name]; !exists {
+			header[name] = values
+		}
+	}
+	header.Set("Idempotent-Replayed", "true")
+
+	c.Writer.WriteHeader(record.Status)
+	_, err := c.Writer.Write(record.Body)
+	return err
+}
+
+// idempotencyWriter passes the response through while recording its status,
+// headers and body
+type idempotencyWriter struct {
+	router.ResponseWriter
+	max      int
+	status   int
+	header   http.Header
+	written  bool
+	overflow bool
+	buf      bytes.Buffer
+}
+
+// WriteHeader records the status and the headers sent with it
+func (w *idempotencyWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+		w.header = w.ResponseWriter.Header().Clone()
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the data up to the limit
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.overflow {
+		if w.max > 0 && w.buf.Len()+len(data) > w.max {
+			w.overflow = true
+			w.buf = bytes.Buffer{}
+		} else {
+			w.buf.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends buffered data to the client
+func (w *idempotencyWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap returns the underlying writer, e.g. for http.ResponseController
+func (w *idempotencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
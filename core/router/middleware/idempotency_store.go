@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// idempotencyLockTimeout is how long a request holds its key. A key whose
+// request never completed, e.g. because the process died, can be retried
+// afterwards.
+const idempotencyLockTimeout = 5 * time.Minute
+
+// idempotencyCleanupInterval is how often expired keys are deleted
+const idempotencyCleanupInterval = time.Hour
+
+var (
+	// ErrIdempotencyInProgress is returned while another request holds the key
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is in progress")
+
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a
+	// different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+)
+
+// IdempotencyRecord is a stored idempotency key with the response of the
+// request that used it. Status is zero while the request is in progress.
+type IdempotencyRecord struct {
+	Id          uint      `json:"id" gorm:"primaryKey"`
+	Scope       string    `json:"scope" gorm:"size:191;uniqueIndex:idx_idempotency_scope_key"`
+	Key         string    `json:"key" gorm:"column:idempotency_key;size:191;uniqueIndex:idx_idempotency_scope_key"`
+	Fingerprint string    `json:"fingerprint" gorm:"size:64"`
+	Attempt     int       `json:"attempt"`
+	Status      int       `json:"status"`
+	Header      string    `json:"header" gorm:"type:text"`
+	Body        []byte    `json:"-"`
+	LockedUntil time.Time `json:"locked_until"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the IdempotencyRecord model
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// ResponseHeader returns the stored response headers
+func (r *IdempotencyRecord) ResponseHeader() http.Header {
+	header := http.Header{}
+	if r.Header != "" {
+		_ = json.Unmarshal([]byte(r.Header), &header)
+	}
+	return header
+}
+
+// IdempotencyStore stores idempotency keys and the responses of the
+// requests that used them
+type IdempotencyStore interface {
+	// Lock claims a key for a request. It returns a nil record and the
+	// attempt holding the key if the caller now holds it, the completed
+	// record to replay, ErrIdempotencyInProgress or ErrIdempotencyKeyReused.
+	Lock(ctx context.Context, scope, key, fingerprint string) (*IdempotencyRecord, int, error)
+
+	// Save stores the response of the request holding the key, unless a
+	// retry took the key over from the attempt
+	Save(ctx context.Context, scope, key string, attempt, status int, header http.Header, body []byte) error
+
+	// Unlock releases a key without a response so that it can be retried,
+	// unless a retry took the key over from the attempt
+	Unlock(ctx context.Context, scope, key string, attempt int) error
+}
+
+// GormIdempotencyStore stores idempotency keys in the database. Keys expire
+// after the TTL and are deleted periodically until Stop is called.
+type GormIdempotencyStore struct {
+	db       *gorm.DB
+	ttl      time.Duration
+	cleanup  *time.Ticker
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewGormIdempotencyStore creates the idempotency_keys table if needed and
+// starts deleting expired keys
+func NewGormIdempotencyStore(db *gorm.DB, ttl time.Duration) (*GormIdempotencyStore, error) {
+	if err := db.AutoMigrate(&IdempotencyRecord{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate idempotency keys table: %w", err)
+	}
+
+	s := &GormIdempotencyStore{
+		db:      db,
+		ttl:     ttl,
+		cleanup: time.NewTicker(idempotencyCleanupInterval),
+		done:    make(chan struct{}),
+	}
+	go s.cleanupRoutine()
+	return s, nil
+}
+
+// Lock claims a key for a request
+func (s *GormIdempotencyStore) Lock(ctx context.Context, scope, key, fingerprint string) (*IdempotencyRecord, int, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	record := &IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(idempotencyLockTimeout),
+		ExpiresAt:   now.Add(s.ttl),
+	}
+	createErr := db.Create(record).Error
+	if createErr == nil {
+		return nil, record.Attempt, nil
+	}
+
+	// The unique index rejected the key: look at the request that used it
+	var existing IdempotencyRecord
+	if err := db.Where("scope = ? AND idempotency_key = ?", scope, key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, createErr
+		}
+		return nil, 0, err
+	}
+
+	switch {
+	case existing.ExpiresAt.Before(now):
+		// Expired keys may be used for a new request
+	case existing.Fingerprint != fingerprint:
+		return nil, 0, ErrIdempotencyKeyReused
+	case existing.Status != 0:
+		return &existing, 0, nil
+	case existing.LockedUntil.After(now):
+		return nil, 0, ErrIdempotencyInProgress
+	}
+
+	// Take over an expired or abandoned key, unless a concurrent retry was
+	// faster
+	result := db.Model(&IdempotencyRecord{}).
+		Where("id = ? AND attempt = ?", existing.Id, existing.Attempt).
+		Updates(map[string]any{
+			"fingerprint":  fingerprint,
+			"attempt":      existing.Attempt + 1,
+			"status":       0,
+			"header":       "",
+			"body":         nil,
+			"locked_until": now.Add(idempotencyLockTimeout),
+			"expires_at":   now.Add(s.ttl),
+		})
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, 0, ErrIdempotencyInProgress
+	}
+	return nil, existing.Attempt + 1, nil
+}
+
+// Save stores the response of the request holding the key
+func (s *GormIdempotencyStore) Save(ctx context.Context, scope, key string, attempt, status int, header http.Header, body []byte) error {
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Model(&IdempotencyRecord{}).
+		Where("scope = ? AND idempotency_key = ? AND attempt = ? AND status = 0", scope, key, attempt).
+		Updates(map[string]any{
+			"status":       status,
+			"header":       string(encoded),
+			"body":         body,
+			"locked_until": time.Now(),
+		}).Error
+}
+
+// Unlock releases a key without a response
+func (s *GormIdempotencyStore) Unlock(ctx context.Context, scope, key string, attempt int) error {
+	return s.db.WithContext(ctx).
+		Where("scope = ? AND idempotency_key = ? AND attempt = ? AND status = 0", scope, key, attempt).
+		Delete(&IdempotencyRecord{}).Error
+}
+
+// Purge deletes expired keys
+func (s *GormIdempotencyStore) Purge(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
+
+// cleanupRoutine deletes expired keys periodically
+func (s *GormIdempotencyStore) cleanupRoutine() {
+	for {
+		select {
+		case <-s.cleanup.C:
+			_, _ = s.Purge(context.Background())
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop stops deleting expired keys
+func (s *GormIdempotencyStore) Stop() {
+	s.stopOnce.Do(func() {
+		s.cleanup.Stop()
+		close(s.done)
+	})
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -113,6 +114,11 @@ func Recovery(log logger.Logger) router.MiddlewareFunc {
 		return func(c *router.Context) (err error) {
 			defer func() {
 				if r := recover(); r != nil {
+					if r == http.ErrAbortHandler {
+						// Deliberate abort, e.g. by a timed out handler
+						panic(r)
+					}
+
 					// Log the panic if logger is available
 					if log != nil {
 						log.Error("Panic recovered",
@@ -174,6 +174,49 @@ func RateLimit(config *RateLimitConfig) router.MiddlewareFunc {
 	}
 }
 
+// rateLimitKeyFuncs holds the key functions rate limits can refer to by name
+var (
+	rateLimitKeyFuncs = map[string]func(*router.Context) string{
+		"ip": func(c *router.Context) string {
+			return c.ClientIP()
+		},
+		"user": func(c *router.Context) string {
+			if userID, exists := c.Get("user_id"); exists {
+				return fmt.Sprintf("user:%v", userID)
+			}
+			return c.ClientIP()
+		},
+		"api_key": func(c *router.Context) string {
+			if apiKey := c.Header("X-Api-Key"); apiKey != "" {
+				return "api_key:" + apiKey
+			}
+			return c.ClientIP()
+		},
+	}
+	rateLimitKeyFuncsMu sync.RWMutex
+)
+
+// RegisterRateLimitKeyFunc registers a named key function that module rate
+// limits can select with RateLimitConfig.KeyFunc
+func RegisterRateLimitKeyFunc(name string, keyFunc func(*router.Context) string) {
+	rateLimitKeyFuncsMu.Lock()
+	defer rateLimitKeyFuncsMu.Unlock()
+	rateLimitKeyFuncs[name] = keyFunc
+}
+
+// rateLimitKey extracts the rate limit key with the named key function,
+// falling back to the client IP for unknown names
+func rateLimitKey(name string, c *router.Context) string {
+	rateLimitKeyFuncsMu.RLock()
+	keyFunc, ok := rateLimitKeyFuncs[name]
+	rateLimitKeyFuncsMu.RUnlock()
+
+	if !ok {
+		return c.ClientIP()
+	}
+	return keyFunc(c)
+}
+
 // PerEndpointRateLimit creates per-endpoint rate limiting
 func PerEndpointRateLimit(requests int, duration time.Duration) router.MiddlewareFunc {
 	limiter := NewTokenBucket(requests, duration, requests)
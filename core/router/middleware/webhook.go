@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"base/core/config"
+	"base/core/router"
+)
+
+// stripeSignatureTolerance is the maximum age of a Stripe signature timestamp
+const stripeSignatureTolerance = 5 * time.Minute
+
+// webhookProviderDefaults holds the signature header and secret variable used
+// when a WebhookSignatureConfig leaves them empty
+var webhookProviderDefaults = map[string]config.WebhookSignatureConfig{
+	"stripe": {Header: "Stripe-Signature", SecretEnvVar: "STRIPE_WEBHOOK_SECRET", Algorithm: "sha256"},
+	"github": {Header: "X-Hub-Signature-256", SecretEnvVar: "GITHUB_WEBHOOK_SECRET", Algorithm: "sha256"},
+}
+
+// VerifyWebhookSignature checks the HMAC signature of the request body. Stripe
+// signatures ("t=...,v1=...") and GitHub style "sha256=<hex>" signatures are
+// supported, as well as plain hex or base64 HMACs of the body. The body is
+// restored so handlers can read it again. Bodies larger than cfg.MaxBodySize
+// fail with an *http.MaxBytesError.
+func VerifyWebhookSignature(c *router.Context, cfg *config.WebhookSignatureConfig) error {
+	provider := strings.ToLower(cfg.Provider)
+	settings := *cfg
+	if defaults, ok := webhookProviderDefaults[provider]; ok {
+		if settings.Header == "" {
+			settings.Header = defaults.Header
+		}
+		if settings.SecretEnvVar == "" {
+			settings.SecretEnvVar = defaults.SecretEnvVar
+		}
+		if settings.Algorithm == "" {
+			settings.Algorithm = defaults.Algorithm
+		}
+	}
+
+	if settings.Header == "" || settings.SecretEnvVar == "" {
+		return errors.New("signature header and secret must be configured")
+	}
+	secret := os.Getenv(settings.SecretEnvVar)
+	if secret == "" {
+		return errors.New("webhook secret is not set")
+	}
+
+	signature := c.Header(settings.Header)
+	if signature == "" {
+		return errors.New("missing signature header")
+	}
+
+	newHash, err := webhookHash(settings.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	maxBodySize := settings.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = config.DefaultWebhookMaxBodySize
+	}
+	if c.Request.ContentLength > maxBodySize {
+		return &http.MaxBytesError{Limit: maxBodySize}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		return errors.New("failed to read request body")
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if provider == "stripe" {
+		return verifyStripeSignature(signature, body, secret, newHash)
+	}
+	return verifyHMACSignature(signature, body, secret, newHash)
+}
+
+// verifyStripeSignature verifies a "t=<timestamp>,v1=<hex>" signature of
+// "<timestamp>.<body>"
+func verifyStripeSignature(header string, body []byte, secret string, newHash func() hash.Hash) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("malformed signature header")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("malformed signature timestamp")
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return errors.New("signature timestamp outside tolerance")
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.New("signature mismatch")
+}
+
+// verifyHMACSignature verifies a hex or base64 HMAC of the body, optionally
+// prefixed with the algorithm ("sha256=<hex>")
+func verifyHMACSignature(signature string, body []byte, secret string, newHash func() hash.Hash) error {
+	if algorithm, value, ok := strings.Cut(signature, "="); ok {
+		if _, err := webhookHash(algorithm); err == nil {
+			signature = value
+		}
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return nil
+	}
+	return errors.New("signature mismatch")
+}
+
+// webhookHash returns the hash constructor for an algorithm name
+func webhookHash(algorithm string) (func() hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha1":
+		return sha1.New, nil
+	case "sha512":
+		return sha512.New, nil
+	default:
+		return nil, errors.New("unsupported signature algorithm " + algorithm)
+	}
+}
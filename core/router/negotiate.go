@@ -0,0 +1,310 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"base/core/types"
+)
+
+// Encoder writes responses in one format. The format name is selected with
+// the ?format= query parameter, the media types with the Accept header.
+//
+//	router.RegisterEncoder(router.Encoder{
+//		Format:     "msgpack",
+//		MediaTypes: []string{"application/msgpack", "application/x-msgpack"},
+//		Encode: func(w io.Writer, data any) error {
+//			return msgpack.NewEncoder(w).Encode(data)
+//		},
+//	})
+type Encoder struct {
+	Format      string   // e.g. "csv"
+	MediaTypes  []string // accepted media types; the first is the default Content-Type
+	ContentType string   // Content-Type of responses, if it differs from the first media type
+	Encode      func(w io.Writer, data any) error
+}
+
+// contentType returns the Content-Type header of responses
+func (e Encoder) contentType() string {
+	if e.ContentType != "" {
+		return e.ContentType
+	}
+	return e.MediaTypes[0]
+}
+
+var (
+	encoders   = defaultEncoders()
+	encodersMu sync.RWMutex
+)
+
+// RegisterEncoder adds an encoder, replacing the encoder of the same format.
+// Encoders registered later lose ties against earlier ones, so JSON stays the
+// default for clients accepting anything.
+func RegisterEncoder(encoder Encoder) {
+	if encoder.Format == "" || len(encoder.MediaTypes) == 0 || encoder.Encode == nil {
+		panic("router: encoder needs a format, a media type and an Encode function")
+	}
+
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	for i, existing := range encoders {
+		if existing.Format == encoder.Format {
+			encoders[i] = encoder
+			return
+		}
+	}
+	encoders = append(encoders, encoder)
+}
+
+// Formats returns the names of the registered encoders
+func Formats() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	formats := make([]string, len(encoders))
+	for i, encoder := range encoders {
+		formats[i] = encoder.Format
+	}
+	return formats
+}
+
+// Negotiate writes data in the format the client asks for with the ?format=
+// query parameter or the Accept header, defaulting to JSON. Browsers, whose
+// Accept headers list HTML, receive JSON as well when they accept it. Clients
+// accepting none of the registered formats receive 406 Not Acceptable.
+//
+// For CSV and NDJSON, slices are written one record per element; a
+// types.PaginatedResponse is written as its data, and its pagination is sent
+// in the X-Total-Count, X-Page, X-Page-Size and X-Total-Pages headers.
+func (c *Context) Negotiate(code int, data any) error {
+	encoder, ok := c.negotiateEncoder()
+	if !ok {
+		return c.JSON(http.StatusNotAcceptable, typedError{
+			Error:   "none of the requested formats is available",
+			Details: map[string][]string{"formats": Formats()},
+		})
+	}
+
+	// Encode first so that errors can still be reported with a status
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, data); err != nil {
+		return fmt.Errorf("encode %s response: %w", encoder.Format, err)
+	}
+
+	if page, ok := paginated(data); ok {
+		c.SetHeader("X-Total-Count", strconv.Itoa(page.Pagination.Total))
+		c.SetHeader("X-Page", strconv.Itoa(page.Pagination.Page))
+		c.SetHeader("X-Page-Size", strconv.Itoa(page.Pagination.PageSize))
+		c.SetHeader("X-Total-Pages", strconv.Itoa(page.Pagination.TotalPages))
+	}
+	AddVary(c.Writer.Header(), "Accept")
+	return c.Data(code, encoder.contentType(), buf.Bytes())
+}
+
+// NegotiatedFormat returns the format Negotiate would respond with
+func (c *Context) NegotiatedFormat() (string, bool) {
+	encoder, ok := c.negotiateEncoder()
+	return encoder.Format, ok
+}
+
+// negotiateEncoder picks the encoder for the request
+func (c *Context) negotiateEncoder() (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		for _, encoder := range encoders {
+			if encoder.Format == format {
+				return encoder, true
+			}
+		}
+		return Encoder{}, false
+	}
+
+	accept := c.Header("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0], true
+	}
+
+	ranges := parseAccept(accept)
+	// Browsers navigating to a URL list HTML first and XML above */*; such
+	// lists get the default format when it is accepted at all
+	if browserAccept(ranges) {
+		if _, ok := matchAccept(ranges, encoders[0].MediaTypes); ok {
+			return encoders[0], true
+		}
+	}
+
+	var (
+		best      Encoder
+		bestMatch acceptMatch
+		found     bool
+	)
+	for _, encoder := range encoders {
+		match, ok := matchAccept(ranges, encoder.MediaTypes)
+		if ok && (!found || match.better(bestMatch)) {
+			best, bestMatch, found = encoder, match, true
+		}
+	}
+	return best, found
+}
+
+// browserAccept reports whether the ranges are a browser's navigation
+// header: they accept HTML, which no registered encoder writes
+func browserAccept(ranges []acceptRange) bool {
+	html := false
+	for _, r := range ranges {
+		if r.mediaType == "text/html" && r.q > 0 {
+			html = true
+		}
+	}
+	if !html {
+		return false
+	}
+	for _, encoder := range encoders {
+		for _, mediaType := range encoder.MediaTypes {
+			if mediaType == "text/html" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// acceptRange is one media range of an Accept header
+type acceptRange struct {
+	mediaType string
+	q         float64
+	index     int
+}
+
+// acceptMatch ranks how well an Accept header matches an encoder
+type acceptMatch struct {
+	q           float64
+	specificity int // 2 exact, 1 type/*, 0 */*
+	index       int // position of the range in the header
+}
+
+// better reports whether m is preferred over other: higher quality first,
+// then the more specific range, then the range listed first
+func (m acceptMatch) better(other acceptMatch) bool {
+	if m.q != other.q {
+		return m.q > other.q
+	}
+	if m.specificity != other.specificity {
+		return m.specificity > other.specificity
+	}
+	return m.index < other.index
+}
+
+// parseAccept parses the media ranges of an Accept header
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for i, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q, index: i})
+	}
+	return ranges
+}
+
+// matchAccept finds the most specific range matching one of the media types.
+// A range with q=0 excludes the media type.
+func matchAccept(ranges []acceptRange, mediaTypes []string) (acceptMatch, bool) {
+	var (
+		best  acceptMatch
+		found bool
+	)
+	for _, mediaType := range mediaTypes {
+		typ, _, _ := strings.Cut(mediaType, "/")
+
+		var (
+			match   acceptMatch
+			matched bool
+		)
+		for _, r := range ranges {
+			specificity := -1
+			switch {
+			case r.mediaType == mediaType || suffixMatches(r.mediaType, mediaType):
+				specificity = 2
+			case r.mediaType == typ+"/*":
+				specificity = 1
+			case r.mediaType == "*/*" || r.mediaType == "*":
+				specificity = 0
+			}
+			if specificity < 0 || (matched && specificity <= match.specificity) {
+				continue
+			}
+			match, matched = acceptMatch{q: r.q, specificity: specificity, index: r.index}, true
+		}
+		if matched && match.q > 0 && (!found || match.better(best)) {
+			best, found = match, true
+		}
+	}
+	return best, found
+}
+
+// EncodingQuality returns the quality an Accept-Encoding header gives a
+// content coding such as "gzip". A coding listed by name overrides the
+// wildcard and x-gzip counts as gzip; 0 means the coding is refused or not
+// accepted.
+func EncodingQuality(header, coding string) float64 {
+	coding = strings.ToLower(coding)
+	q, listed, wildcard := 0.0, false, 0.0
+	// Accept-Encoding has the syntax of Accept, with codings for media ranges
+	for _, r := range parseAccept(header) {
+		name := r.mediaType
+		if name == "x-gzip" {
+			name = "gzip"
+		}
+		switch name {
+		case coding:
+			q, listed = r.q, true
+		case "*":
+			wildcard = r.q
+		}
+	}
+	if !listed {
+		return wildcard
+	}
+	return q
+}
+
+// paginated returns the paginated response data wraps, if any
+func paginated(data any) (*types.PaginatedResponse, bool) {
+	switch v := data.(type) {
+	case types.PaginatedResponse:
+		return &v, true
+	case *types.PaginatedResponse:
+		return v, v != nil
+	}
+	return nil, false
+}
+
+// suffixMatches reports whether a vendor media type with a structured
+// syntax suffix, e.g. application/vnd.base.v2+json, names mediaType. Other
+// suffixed types such as application/xhtml+xml are formats of their own.
+func suffixMatches(vendorType, mediaType string) bool {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	vendorTyp, vendorSubtype, _ := strings.Cut(vendorType, "/")
+	return typ == vendorTyp && strings.HasPrefix(vendorSubtype, "vnd.") &&
+		strings.HasSuffix(vendorSubtype, "+"+subtype)
+}
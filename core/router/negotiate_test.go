@@ -0,0 +1,55 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	r := New()
+	r.GET("/users", func(c *Context) error {
+		return c.Negotiate(http.StatusOK, []map[string]string{{"name": "ada"}})
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		query       string
+		status      int
+		contentType string
+	}{
+		{name: "no Accept header", status: http.StatusOK, contentType: "application/json"},
+		{name: "anything", accept: "*/*", status: http.StatusOK, contentType: "application/json"},
+		{name: "browser navigation", accept: chromeAccept, status: http.StatusOK, contentType: "application/json"},
+		{name: "firefox navigation", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", status: http.StatusOK, contentType: "application/json"},
+		{name: "browser without a wildcard", accept: "text/html,application/xml;q=0.9", status: http.StatusOK, contentType: "application/xml"},
+		{name: "xhtml is not xml", accept: "application/xhtml+xml", status: http.StatusNotAcceptable},
+		{name: "xml", accept: "application/xml", status: http.StatusOK, contentType: "application/xml"},
+		{name: "xml preferred over anything", accept: "application/xml, */*;q=0.8", status: http.StatusOK, contentType: "application/xml"},
+		{name: "vendor xml", accept: "application/vnd.base.v2+xml", status: http.StatusOK, contentType: "application/xml"},
+		{name: "vendor json", accept: "application/vnd.base.v2+json", status: http.StatusOK, contentType: "application/json"},
+		{name: "higher quality wins", accept: "application/json;q=0.5, text/csv", status: http.StatusOK, contentType: "text/csv"},
+		{name: "excluded", accept: "application/json;q=0", status: http.StatusNotAcceptable},
+		{name: "format parameter", accept: chromeAccept, query: "?format=csv", status: http.StatusOK, contentType: "text/csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.contentType != "" && !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.contentType) {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.contentType)
+			}
+		})
+	}
+}
@@ -72,6 +72,11 @@ func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
 	return http.ErrNotSupported
 }
 
+// Unwrap returns the underlying writer, e.g. for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // ResponseWriter interface extends http.ResponseWriter
 type ResponseWriter interface {
 	http.ResponseWriter
@@ -82,3 +87,17 @@ type ResponseWriter interface {
 	Flush()
 	Push(target string, opts *http.PushOptions) error
 }
+
+// headResponseWriter discards the body written by a GET handler answering a
+// HEAD request
+type headResponseWriter struct {
+	ResponseWriter
+}
+
+// Write discards the data
+func (w *headResponseWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	return len(data), nil
+}
@@ -1,31 +1,58 @@
 package router
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"path"
+	"os"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 )
 
-// Router is a lightweight HTTP router with middleware support
+// Router is a lightweight HTTP router with middleware support. Its own
+// routes form the default host; see Host for virtual hosts.
 type Router struct {
-	trees      map[string]*node // HTTP method -> route tree
-	middleware []MiddlewareFunc
-	notFound   HandlerFunc
-	pool       sync.Pool
-	mu         sync.RWMutex
+	routeSet
+	hosts        []*Host // virtual hosts, most specific first
+	routes       []*Route
+	names        map[string]*Route // route name -> route
+	shutdown     chan struct{}     // closed when the server shuts down, ends streams
+	shutdownOnce sync.Once
+	pool         sync.Pool
+	mu           sync.RWMutex
+
+	deprecatedUses sync.Map // deprecatedUseKey -> *deprecatedUseCount
+}
+
+// routeSet holds the routes and handlers of a host
+type routeSet struct {
+	trees            map[string]*node // HTTP method -> route tree
+	middleware       []MiddlewareFunc
+	notFound         HandlerFunc
+	methodNotAllowed HandlerFunc
+	versioned        map[string]*versionedRoute // "METHOD path" -> versions
+	versionTrees     map[string]*node           // HTTP method -> versioned routes
+	versionPrefixes  []string                   // group prefixes a version segment may follow
 }
 
 // New creates a new router
 func New() *Router {
 	r := &Router{
-		trees:    make(map[string]*node),
-		notFound: defaultNotFound,
+		routeSet: routeSet{
+			trees:            make(map[string]*node),
+			notFound:         defaultNotFound,
+			methodNotAllowed: defaultMethodNotAllowed,
+		},
+		names:    make(map[string]*Route),
+		shutdown: make(chan struct{}),
 	}
 	r.pool.New = func() any {
 		return &Context{
 			params: make(Params, 0, 10),
 			keys:   make(map[string]any),
+			router: r,
 		}
 	}
 	return r
@@ -39,46 +66,55 @@ type MiddlewareFunc func(HandlerFunc) HandlerFunc
 
 // Use adds global middleware
 func (r *Router) Use(middleware ...MiddlewareFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.middleware = append(r.middleware, middleware...)
 }
 
 // GET registers a GET route
-func (r *Router) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodGet, path, handler, middleware...)
+func (r *Router) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodGet, path, handler, middleware...)
 }
 
 // POST registers a POST route
-func (r *Router) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodPost, path, handler, middleware...)
+func (r *Router) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPost, path, handler, middleware...)
 }
 
 // PUT registers a PUT route
-func (r *Router) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodPut, path, handler, middleware...)
+func (r *Router) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPut, path, handler, middleware...)
 }
 
 // DELETE registers a DELETE route
-func (r *Router) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodDelete, path, handler, middleware...)
+func (r *Router) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodDelete, path, handler, middleware...)
 }
 
 // PATCH registers a PATCH route
-func (r *Router) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodPatch, path, handler, middleware...)
+func (r *Router) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodPatch, path, handler, middleware...)
 }
 
 // HEAD registers a HEAD route
-func (r *Router) HEAD(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodHead, path, handler, middleware...)
+func (r *Router) HEAD(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodHead, path, handler, middleware...)
 }
 
 // OPTIONS registers an OPTIONS route
-func (r *Router) OPTIONS(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	r.Handle(http.MethodOptions, path, handler, middleware...)
+func (r *Router) OPTIONS(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Handle(http.MethodOptions, path, handler, middleware...)
 }
 
 // Handle registers a route with the given method and path
-func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle(&Route{Method: method, Path: path}, handler, middleware)
+}
+
+// handle adds a route to the method tree of its host
+func (r *Router) handle(route *Route, handler HandlerFunc, middleware []MiddlewareFunc) *Route {
+	method, path := route.Method, route.Path
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -86,22 +122,59 @@ func (r *Router) Handle(method, path string, handler HandlerFunc, middleware ...
 		panic("path must begin with '/'")
 	}
 
-	root := r.trees[method]
+	set := &r.routeSet
+	if route.Host != "" {
+		set = &r.hostByPattern(route.Host).routeSet
+	}
+
+	root := set.trees[method]
 	if root == nil {
 		root = new(node)
-		r.trees[method] = root
+		set.trees[method] = root
+	}
+
+	route.router = r
+	route.state = &routeState{}
+	if route.Deprecation != nil {
+		route.state.deprecation.Store(route.Deprecation)
 	}
+	route.Middleware = middlewareNames(append(append([]MiddlewareFunc(nil), set.middleware...), middleware...))
+	r.routes = append(r.routes, route)
 
-	// Apply middleware in correct order: global -> route-specific
-	finalHandler := handler
+	// Apply middleware in correct order: global -> route-specific, with the
+	// limits they set enforced around the handler
+	finalHandler := r.deprecationHandler(route, enforceLimits(handler))
 	for i := len(middleware) - 1; i >= 0; i-- {
 		finalHandler = middleware[i](finalHandler)
 	}
-	for i := len(r.middleware) - 1; i >= 0; i-- {
-		finalHandler = r.middleware[i](finalHandler)
+
+	// Versions of a route share one tree entry choosing between them
+	if route.Version != "" {
+		key := method + " " + path
+		if vr := set.versioned[key]; vr != nil {
+			vr.add(route.Version, finalHandler, path)
+			return route
+		}
+		vr := &versionedRoute{router: r}
+		vr.add(route.Version, finalHandler, path)
+		if set.versioned == nil {
+			set.versioned = make(map[string]*versionedRoute)
+			set.versionTrees = make(map[string]*node)
+		}
+		set.versioned[key] = vr
+		if set.versionTrees[method] == nil {
+			set.versionTrees[method] = new(node)
+		}
+		set.versionTrees[method].addRoute(path, vr.serve)
+		finalHandler = vr.serve
+	}
+
+	for i := len(set.middleware) - 1; i >= 0; i-- {
+		finalHandler = set.middleware[i](finalHandler)
 	}
 
 	root.addRoute(path, finalHandler)
+	return route
 }
 
 // Group creates a new route group with prefix
@@ -122,65 +195,157 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.handleRequest(c)
 }
 
-// handleRequest processes the HTTP request
+// handleRequest processes the HTTP request on the host it is addressed to. A
+// path registered under other methods answers 405 with an Allow header, HEAD
+// falls back to the GET route and OPTIONS is answered from the registered
+// methods.
 func (r *Router) handleRequest(c *Context) {
-	r.mu.RLock()
-	root := r.trees[c.Request.Method]
-	r.mu.RUnlock()
+	method := c.Request.Method
+	set, hostParams := r.matchHost(c.Request.Host)
 
-	if root != nil {
-		// Normalize path: remove trailing slash except for root "/"
-		reqPath := c.Request.URL.Path
-		if len(reqPath) > 1 {
-			reqPath = strings.TrimSuffix(reqPath, "/")
-		}
+	// Normalize path: remove trailing slash except for root "/"
+	reqPath := c.Request.URL.Path
+	if len(reqPath) > 1 {
+		reqPath = strings.TrimSuffix(reqPath, "/")
+	}
 
-		if handler, params, _ := root.getValue(reqPath); handler != nil {
-			c.params = params
-			if err := handler(c); err != nil {
-				c.Error(http.StatusInternalServerError, err)
-			}
+	// A version segment selects the version of a versioned route, unless a
+	// route is registered with the segment. Middleware sees the path without
+	// it, so that path rules apply the same to both spellings.
+	if prefix, segment, ok := r.stripVersion(set, method, reqPath); ok && len(r.allowedMethods(set, reqPath)) == 0 {
+		reqPath = withoutSegment(reqPath, prefix, segment)
+		c.pathVersion = segment[1:]
+		removeVersionSegment(c.Request.URL, prefix, segment)
+	}
+
+	if handler, params := r.lookup(set, method, reqPath); handler != nil {
+		r.serve(c, handler, append(hostParams, params...))
+		return
+	}
+
+	// Answer HEAD with the GET route, without the body
+	if method == http.MethodHead {
+		if handler, params := r.lookup(set, http.MethodGet, reqPath); handler != nil {
+			c.Writer = &headResponseWriter{ResponseWriter: c.Writer}
+			r.serve(c, handler, append(hostParams, params...))
 			return
 		}
 	}
 
-	// Handle 404
-	if err := r.notFound(c); err != nil {
+	if allowed := r.allowedMethods(set, reqPath); len(allowed) > 0 {
+		allow := strings.Join(allowed, ", ")
+		handler := set.methodNotAllowed
+		if handler == nil {
+			handler = r.methodNotAllowed
+		}
+		if method == http.MethodOptions {
+			handler = defaultOptions
+		}
+		// Global middleware runs so that e.g. CORS headers are set
+		r.serve(c, r.withMiddleware(set, func(c *Context) error {
+			c.SetHeader("Allow", allow)
+			return handler(c)
+		}), hostParams)
+		return
+	}
+
+	// Handle 404, with the default host's handler unless the host has its own
+	c.params = hostParams
+	notFound := set.notFound
+	if notFound == nil {
+		notFound = r.notFound
+	}
+	if err := notFound(c); err != nil {
 		c.Error(http.StatusInternalServerError, err)
 	}
 }
 
-// NotFound sets the 404 handler
-func (r *Router) NotFound(handler HandlerFunc) {
-	r.notFound = handler
+// lookup finds the handler registered for a method and path
+func (r *Router) lookup(set *routeSet, method, path string) (HandlerFunc, Params) {
+	r.mu.RLock()
+	root := set.trees[method]
+	r.mu.RUnlock()
+
+	if root == nil {
+		return nil, nil
+	}
+	return root.getValue(path)
 }
 
-// Static serves static files
-func (r *Router) Static(prefix, root string) {
-	// Ensure prefix starts with /
-	if !strings.HasPrefix(prefix, "/") {
-		prefix = "/" + prefix
+// serve runs a handler, reporting a returned error as 500, or as 413 and
+// 504 when the body limit or deadline was exceeded, unless the handler has
+// already responded
+func (r *Router) serve(c *Context, handler HandlerFunc, params Params) {
+	c.params = params
+	if err := handler(c); err != nil && !c.Writer.Written() {
+		c.Error(errorStatus(err), err)
 	}
+}
 
-	handler := func(c *Context) error {
-		reqPath := c.Request.URL.Path
+// errorStatus returns the status code of an error returned by a handler
+func errorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
 
-		// Remove the prefix
-		file := strings.TrimPrefix(reqPath, prefix)
-		file = strings.TrimPrefix(file, "/") // clean leading slash
+// allowedMethods returns the methods a path is registered under, including
+// the HEAD and OPTIONS answered automatically
+func (r *Router) allowedMethods(set *routeSet, path string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-		if file == "" || strings.HasSuffix(reqPath, "/") {
-			file = "index.html"
+	var allowed []string
+	for method, root := range set.trees {
+		if handler, _ := root.getValue(path); handler != nil {
+			allowed = append(allowed, method)
 		}
-
-		fullPath := path.Join(root, file)
-		http.ServeFile(c.Writer, c.Request, fullPath)
+	}
+	if len(allowed) == 0 {
 		return nil
 	}
 
-	// register route with wildcard
-	r.GET(prefix+"/*filepath", handler)
-	r.GET(prefix, handler) // also serve the exact prefix URL
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// withMiddleware wraps a handler that is not registered as a route with the
+// middleware of a host
+func (r *Router) withMiddleware(set *routeSet, handler HandlerFunc) HandlerFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(set.middleware) - 1; i >= 0; i-- {
+		handler = set.middleware[i](handler)
+	}
+	return handler
+}
+
+// NotFound sets the 404 handler
+func (r *Router) NotFound(handler HandlerFunc) {
+	r.notFound = handler
+}
+
+// MethodNotAllowed sets the handler for paths that exist under other methods.
+// The Allow header is set before it runs.
+func (r *Router) MethodNotAllowed(handler HandlerFunc) {
+	r.methodNotAllowed = handler
+}
+
+// Static serves static files
+func (r *Router) Static(prefix, root string) {
+	r.StaticFS(prefix, os.DirFS(root))
 }
 
 // defaultNotFound is the default 404 handler
@@ -188,11 +353,54 @@ func defaultNotFound(c *Context) error {
 	return c.String(http.StatusNotFound, "404 page not found")
 }
 
+// defaultMethodNotAllowed is the default 405 handler
+func defaultMethodNotAllowed(c *Context) error {
+	return c.String(http.StatusMethodNotAllowed, "405 method not allowed")
+}
+
+// defaultOptions answers OPTIONS requests for paths without an OPTIONS route
+func defaultOptions(c *Context) error {
+	return c.NoContent()
+}
+
 // RouterGroup represents a group of routes with common prefix and middleware
 type RouterGroup struct {
-	router     *Router
-	prefix     string
-	middleware []MiddlewareFunc
+	router      *Router
+	prefix      string
+	middleware  []MiddlewareFunc
+	module      string
+	host        string // host pattern, empty for the default host
+	version     string // API version of the group's routes, if any
+	deprecation *Deprecation
+}
+
+// WithModule returns a copy of the group whose routes are attributed to the
+// given module
+func (g *RouterGroup) WithModule(name string) *RouterGroup {
+	group := *g
+	group.module = name
+	return &group
+}
+
+// Prefix returns the path prefix of the group
+func (g *RouterGroup) Prefix() string {
+	return g.prefix
+}
+
+// Module returns the module the group's routes are attributed to
+func (g *RouterGroup) Module() string {
+	return g.module
+}
+
+// Routes returns the routes registered by the group's module
+func (g *RouterGroup) Routes() []Route {
+	var routes []Route
+	for _, route := range g.router.Routes() {
+		if route.Module == g.module {
+			routes = append(routes, route)
+		}
+	}
+	return routes
 }
 
 // Use adds middleware to the group
@@ -211,44 +419,49 @@ func (g *RouterGroup) Group(prefix string, middleware ...MiddlewareFunc) *Router
 	normalizedPrefix = strings.ReplaceAll(normalizedPrefix, "//", "/")
 
 	return &RouterGroup{
-		router:     g.router,
-		prefix:     normalizedPrefix,
-		middleware: append(g.middleware, middleware...),
+		router:      g.router,
+		prefix:      normalizedPrefix,
+		middleware:  append(g.middleware, middleware...),
+		module:      g.module,
+		host:        g.host,
+		version:     g.version,
+		deprecation: g.deprecation,
 	}
 }
 
 // GET registers a GET route in the group
-func (g *RouterGroup) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodGet, path, handler, middleware...)
+func (g *RouterGroup) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodGet, path, handler, middleware...)
 }
 
 // POST registers a POST route in the group
-func (g *RouterGroup) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodPost, path, handler, middleware...)
+func (g *RouterGroup) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodPost, path, handler, middleware...)
 }
 
 // PUT registers a PUT route in the group
-func (g *RouterGroup) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodPut, path, handler, middleware...)
+func (g *RouterGroup) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodPut, path, handler, middleware...)
 }
 
 // DELETE registers a DELETE route in the group
-func (g *RouterGroup) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodDelete, path, handler, middleware...)
+func (g *RouterGroup) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodDelete, path, handler, middleware...)
 }
 
 // PATCH registers a PATCH route in the group
-func (g *RouterGroup) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	g.Handle(http.MethodPatch, path, handler, middleware...)
+func (g *RouterGroup) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return g.Handle(http.MethodPatch, path, handler, middleware...)
 }
 
 // Handle registers a route in the group
-func (g *RouterGroup) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+func (g *RouterGroup) Handle(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
 	finalPath := g.prefix + path
 	// Clean up double slashes
 	finalPath = strings.ReplaceAll(finalPath, "//", "/")
 	allMiddleware := append(g.middleware, middleware...)
-	g.router.Handle(method, finalPath, handler, allMiddleware...)
+	route := &Route{Method: method, Path: finalPath, Module: g.module, Host: g.host, Version: g.version, Deprecation: g.deprecation}
+	return g.router.handle(route, handler, allMiddleware)
 }
 
 // Static serves static files for the group
@@ -256,16 +469,8 @@ func (g *RouterGroup) Static(relativePath, root string) {
 	g.router.Static(g.prefix+relativePath, root)
 }
 
-// Run starts the HTTP server
+// Run starts the HTTP server with the default server configuration.
+// Use NewServer directly for custom timeouts and graceful shutdown.
 func (r *Router) Run(addr string) error {
-	if !strings.HasPrefix(addr, ":") {
-		addr = ":" + addr
-	}
-
-	server := &http.Server{
-		Addr:    addr,
-		Handler: r,
-	}
-
-	return server.ListenAndServe()
+	return NewServer(r, nil).Start(addr)
 }
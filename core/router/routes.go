@@ -0,0 +1,212 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Route describes a registered route
+type Route struct {
+	Method      string       `json:"method"`
+	Host        string       `json:"host,omitempty"` // host pattern, empty for the default host
+	Path        string       `json:"path"`
+	Name        string       `json:"name,omitempty"`
+	Module      string       `json:"module,omitempty"`     // module that registered the route, if any
+	Middleware  []string     `json:"middleware,omitempty"` // global then route middleware, outermost first
+	Version     string       `json:"version,omitempty"`    // API version, see RouterGroup.Version
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+
+	router *Router
+	state  *routeState
+}
+
+// Named names the route so that its URL can be built with Router.URL. Names
+// are unique per router, e.g. "users.show"; reusing one panics.
+//
+//	group.GET("/:id", c.Get).Named("users.show")
+func (rt *Route) Named(name string) *Route {
+	if rt.router == nil {
+		panic("route is not registered")
+	}
+
+	r := rt.router
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.names[name]; ok && existing != rt {
+		panic(fmt.Sprintf("route name %q is already used by %s %s", name, existing.Method, existing.Path))
+	}
+	if rt.Name != "" {
+		delete(r.names, rt.Name)
+	}
+	rt.Name = name
+	r.names[name] = rt
+	return rt
+}
+
+// Routes returns the registered routes in registration order
+func (r *Router) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]Route, len(r.routes))
+	for i, route := range r.routes {
+		routes[i] = *route
+		routes[i].Middleware = append([]string(nil), route.Middleware...)
+	}
+	return routes
+}
+
+// Route returns the route registered under a name
+func (r *Router) Route(name string) (Route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	route, ok := r.names[name]
+	if !ok {
+		return Route{}, false
+	}
+	return *route, true
+}
+
+// URL builds the path of a named route. Params fill the route's :param and
+// *catch-all segments and must satisfy their constraints; params the path
+// does not use are added as the query string.
+//
+//	url, err := r.URL("users.show", map[string]any{"id": 42, "tab": "profile"})
+//	// /api/users/42?tab=profile
+func (r *Router) URL(name string, params map[string]any) (string, error) {
+	route, ok := r.Route(name)
+	if !ok {
+		return "", fmt.Errorf("route %q not found", name)
+	}
+
+	used := make(map[string]bool)
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		key, expr := splitWildcard(segment)
+		value, ok := params[key]
+		if !ok {
+			return "", fmt.Errorf("route %q: missing param %q", name, key)
+		}
+		used[key] = true
+
+		s := fmt.Sprint(value)
+		if segment[0] == ':' {
+			if s == "" {
+				return "", fmt.Errorf("route %q: param %q is empty", name, key)
+			}
+			if expr != "" {
+				constraint, err := compileConstraint(expr)
+				if err != nil {
+					return "", fmt.Errorf("route %q: %w", name, err)
+				}
+				if !constraint.match(s) {
+					return "", fmt.Errorf("route %q: param %q does not match <%s>: %q", name, key, expr, s)
+				}
+			}
+			segments[i] = url.PathEscape(s)
+			continue
+		}
+
+		// Catch-all values span segments; escape each of them
+		parts := strings.Split(strings.TrimPrefix(s, "/"), "/")
+		for j, part := range parts {
+			parts[j] = url.PathEscape(part)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+	path := strings.Join(segments, "/")
+
+	// Host params select the virtual host, which is not part of the path
+	for _, label := range strings.Split(route.Host, ".") {
+		if strings.HasPrefix(label, ":") {
+			key, _ := splitWildcard(label)
+			used[key] = true
+		}
+	}
+
+	query := url.Values{}
+	for key, value := range params {
+		if !used[key] {
+			query.Set(key, fmt.Sprint(value))
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// MustURL is like URL but panics if the URL cannot be built
+func (r *Router) MustURL(name string, params map[string]any) string {
+	u, err := r.URL(name, params)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// NamedRoutes returns the names of the named routes, sorted
+func (r *Router) NamedRoutes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closureSuffix matches the suffix the compiler gives closures and method
+// values, e.g. ".func1" or "-fm"
+var closureSuffix = regexp.MustCompile(`(\.func\d+(\.\d+)*|-fm)$`)
+
+// middlewareNames returns readable names of middleware functions, e.g.
+// "middleware.Logger" for the closure returned by middleware.Logger()
+func middlewareNames(middleware []MiddlewareFunc) []string {
+	if len(middleware) == 0 {
+		return nil
+	}
+	names := make([]string, len(middleware))
+	for i, mw := range middleware {
+		names[i] = funcName(mw)
+	}
+	return names
+}
+
+// funcName names a function by its package and function name
+func funcName(fn any) string {
+	fnc := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if fnc == nil {
+		return "unknown"
+	}
+	name := fnc.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for {
+		trimmed := closureSuffix.ReplaceAllString(name, "")
+		if trimmed == name {
+			break
+		}
+		name = trimmed
+	}
+	// Drop the receiver of methods: pkg.(*Type).Method -> pkg.Method
+	if open := strings.Index(name, ".("); open >= 0 {
+		if end := strings.Index(name[open:], ")."); end >= 0 {
+			name = name[:open] + name[open+end+1:]
+		}
+	}
+	return name
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	basehttp "base/core/http"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Server wraps http.Server with the router as its handler and implements
+// the lifecycle defined by basehttp.Server
+type Server struct {
+	router     *Router
+	config     *basehttp.ServerConfig
+	server     *http.Server
+	onShutdown []func()
+	shutdown   bool // Shutdown was called, possibly before the server started
+	mu         sync.Mutex
+}
+
+var _ basehttp.Server = (*Server)(nil)
+
+// NewServer creates a new server for the router with the given configuration
+func NewServer(r *Router, config *basehttp.ServerConfig) *Server {
+	if config == nil {
+		config = basehttp.DefaultServerConfig()
+	}
+
+	return &Server{
+		router: r,
+		config: config,
+	}
+}
+
+// Handler returns the router served by this server
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
+// Config returns the server configuration
+func (s *Server) Config() *basehttp.ServerConfig {
+	return s.config
+}
+
+// Start starts the HTTP server and blocks until it is shut down
+func (s *Server) Start(addr string) error {
+	return s.serve(addr, func(srv *http.Server) error {
+		return srv.ListenAndServe()
+	})
+}
+
+// StartTLS starts the HTTPS server and blocks until it is shut down
+func (s *Server) StartTLS(addr, certFile, keyFile string) error {
+	return s.serve(addr, func(srv *http.Server) error {
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+// serve builds the underlying http.Server and runs the given listen function
+func (s *Server) serve(addr string, listen func(*http.Server) error) error {
+	if !strings.Contains(addr, ":") {
+		addr = ":" + addr
+	}
+
+	s.mu.Lock()
+	// Shutdown before Start is a graceful stop, like Shutdown during serve
+	if s.shutdown {
+		s.mu.Unlock()
+		return nil
+	}
+	if s.server != nil {
+		s.mu.Unlock()
+		return errors.New("server already started")
+	}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.router,
+		ReadTimeout:       s.config.ReadTimeout,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+		MaxHeaderBytes:    s.config.MaxHeaderBytes,
+		TLSConfig:         s.config.TLSConfig,
+	}
+	for _, f := range s.onShutdown {
+		s.server.RegisterOnShutdown(f)
+	}
+	srv := s.server
+	s.mu.Unlock()
+
+	// ErrServerClosed is the expected result of a graceful shutdown
+	if err := listen(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// RegisterOnShutdown registers a function to call when the server shuts down.
+// Useful for notifying hijacked connections such as websockets.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = append(s.onShutdown, f)
+	if s.server != nil {
+		s.server.RegisterOnShutdown(f)
+	}
+}
+
+// Shutdown stops accepting new connections and waits for in-flight requests
+// to complete. If the context expires first, remaining connections are closed.
+// A server shut down before it started does not start.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdown = true
+	srv := s.server
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	if s.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ShutdownTimeout)
+		defer cancel()
+	}
+
+	// Long-lived streams never finish on their own
+	s.router.closeStreams()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		// Drain deadline exceeded - force close remaining connections
+		closeErr := srv.Close()
+		return errors.Join(err, closeErr)
+	}
+	return nil
+}
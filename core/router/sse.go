@@ -0,0 +1,281 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSSEHeartbeat is the interval of the comments Stream sends to keep
+// idle connections from being closed by proxies
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// ErrStreamClosed is returned when writing to a stream whose client
+// disconnected or whose server is shutting down
+var ErrStreamClosed = errors.New("sse: stream closed")
+
+// SSEvent is an event sent to a Server-Sent Events client
+type SSEvent struct {
+	ID    string        // becomes the client's Last-Event-ID
+	Event string        // event type; clients receive "message" when empty
+	Data  any           // strings and []byte are sent as is, other values as JSON
+	Retry time.Duration // reconnection delay advised to the client
+}
+
+// SSEStream writes Server-Sent Events to a client. Its methods are safe for
+// concurrent use.
+type SSEStream struct {
+	c    *Context
+	done <-chan struct{}
+	mu   sync.Mutex
+	err  error
+}
+
+// StreamOption configures Stream and EmitterStream
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	heartbeat time.Duration
+	retry     time.Duration
+	history   int
+	filter    func(client *StreamClient, event string, data any) bool
+}
+
+// WithHeartbeat sets the interval of heartbeat comments; zero disables them
+func WithHeartbeat(interval time.Duration) StreamOption {
+	return func(cfg *streamConfig) {
+		cfg.heartbeat = interval
+	}
+}
+
+// WithRetry advises clients to wait the given delay before reconnecting
+func WithRetry(delay time.Duration) StreamOption {
+	return func(cfg *streamConfig) {
+		cfg.retry = delay
+	}
+}
+
+// SSE starts a Server-Sent Events response: the headers are sent and the
+// server's write timeout and the route's Timeout are lifted for the
+// connection. Prefer Stream, which also sends heartbeats.
+func (c *Context) SSE() (*SSEStream, error) {
+	if c.Writer.Written() {
+		return nil, errors.New("sse: response already written")
+	}
+	if c.liftTimeout != nil && !c.liftTimeout() {
+		return nil, http.ErrHandlerTimeout
+	}
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	header.Del("Content-Length")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	return &SSEStream{c: c, done: c.streamDone()}, nil
+}
+
+// Stream starts a Server-Sent Events response and calls handler with the
+// stream. Heartbeat comments are sent while the handler runs. The handler
+// should return once stream.Done() is closed, i.e. when the client
+// disconnects or the server shuts down; ErrStreamClosed is not reported.
+//
+//	return c.Stream(func(s *router.SSEStream) error {
+//		for {
+//			select {
+//			case p := <-progress:
+//				if err := s.Send(router.SSEvent{Event: "progress", Data: p}); err != nil {
+//					return err
+//				}
+//			case <-s.Done():
+//				return nil
+//			}
+//		}
+//	})
+func (c *Context) Stream(handler func(*SSEStream) error, opts ...StreamOption) error {
+	cfg := &streamConfig{heartbeat: DefaultSSEHeartbeat}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	stream, err := c.SSE()
+	if err != nil {
+		return err
+	}
+	if cfg.retry > 0 {
+		if err := stream.Send(SSEvent{Retry: cfg.retry}); err != nil {
+			return ignoreStreamClosed(err)
+		}
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	if cfg.heartbeat > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(cfg.heartbeat)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if stream.Comment("heartbeat") != nil {
+						return
+					}
+				case <-stop:
+					return
+				case <-stream.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	err = handler(stream)
+	close(stop)
+	wg.Wait()
+	return ignoreStreamClosed(err)
+}
+
+// LastEventID returns the ID of the last event the client received before
+// reconnecting, from the Last-Event-ID header or the lastEventId query
+// parameter used by EventSource polyfills
+func (s *SSEStream) LastEventID() string {
+	if id := s.c.Header("Last-Event-ID"); id != "" {
+		return id
+	}
+	return s.c.Query("lastEventId")
+}
+
+// Done is closed when the client disconnects or the server shuts down
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Context returns the router context of the request
+func (s *SSEStream) Context() *Context {
+	return s.c
+}
+
+// Send writes an event and flushes it to the client
+func (s *SSEStream) Send(event SSEvent) error {
+	var buf bytes.Buffer
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", singleLine(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", singleLine(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	if event.Data != nil {
+		data, err := encodeSSEData(event.Data)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(data, "\n") {
+			fmt.Fprintf(&buf, "data: %s\n", line)
+		}
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.Bytes())
+}
+
+// Comment writes a comment, which clients ignore; used for heartbeats
+func (s *SSEStream) Comment(text string) error {
+	return s.write([]byte(": " + singleLine(text) + "\n\n"))
+}
+
+// write writes and flushes a chunk unless the stream is closed
+func (s *SSEStream) write(chunk []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	select {
+	case <-s.done:
+		s.err = ErrStreamClosed
+		return s.err
+	default:
+	}
+
+	if _, err := s.c.Writer.Write(chunk); err != nil {
+		s.err = fmt.Errorf("%w: %v", ErrStreamClosed, err)
+		return s.err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// streamDone returns a channel closed when the request ends or the router's
+// server shuts down
+func (c *Context) streamDone() <-chan struct{} {
+	if c.router == nil {
+		return c.Request.Context().Done()
+	}
+
+	done := make(chan struct{})
+	ctx := c.Request.Context()
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+		case <-c.router.shutdown:
+		}
+	}()
+	return done
+}
+
+// closeStreams ends the streams of the router, e.g. on shutdown
+func (r *Router) closeStreams() {
+	r.shutdownOnce.Do(func() {
+		close(r.shutdown)
+	})
+}
+
+// encodeSSEData renders event data, normalizing line breaks to "\n"
+func encodeSSEData(data any) (string, error) {
+	var s string
+	switch v := data.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("sse: encode data: %w", err)
+		}
+		s = string(b)
+	}
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s), nil
+}
+
+// singleLine removes line breaks from a field value
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// ignoreStreamClosed drops the error reported for a closed stream
+func ignoreStreamClosed(err error) error {
+	if errors.Is(err, ErrStreamClosed) {
+		return nil
+	}
+	return err
+}
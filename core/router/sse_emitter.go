@@ -0,0 +1,287 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"base/core/emitter"
+)
+
+// Default number of events EmitterStream keeps for reconnecting clients
+const defaultStreamHistory = 100
+
+// emitterClientBuffer is the number of events queued per client; clients
+// that fall further behind are disconnected and catch up on reconnect
+const emitterClientBuffer = 64
+
+// WithHistory sets how many events an EmitterStream keeps to replay to
+// clients reconnecting with Last-Event-ID; zero disables replay
+func WithHistory(events int) StreamOption {
+	return func(cfg *streamConfig) {
+		cfg.history = events
+	}
+}
+
+// WithEventFilter decides per client whether an event is sent, e.g. to
+// deliver notifications only to the user they concern:
+//
+//	router.WithEventFilter(func(client *router.StreamClient, event string, data any) bool {
+//		return data.(*Notification).UserId == client.UserID
+//	})
+func WithEventFilter(filter func(client *StreamClient, event string, data any) bool) StreamOption {
+	return func(cfg *streamConfig) {
+		cfg.filter = filter
+	}
+}
+
+// EmitterStream relays events of an emitter.Emitter to Server-Sent Events
+// clients. Events get increasing IDs and the most recent ones are kept, so
+// that a reconnecting client receives the events it missed. Clients may
+// narrow the events with the events query parameter, e.g. ?events=a,b.
+//
+//	notifications := router.NewEmitterStream(app.emitter, []string{"media.uploaded"})
+//	api.GET("/events", notifications.Handler())
+type EmitterStream struct {
+	events      []string
+	cfg         *streamConfig
+	opts        []StreamOption
+	unsubscribe []func()
+	closed      chan struct{}
+	closeOnce   sync.Once
+
+	mu      sync.Mutex
+	seq     uint64
+	history []emitterEvent
+	clients map[*emitterClient]struct{}
+}
+
+// emitterEvent is a relayed event and its sequence number
+type emitterEvent struct {
+	seq   uint64
+	event SSEvent
+}
+
+// StreamClient describes a connected client of an EmitterStream to its
+// event filter. It is captured when the client connects, as the request's
+// Context may be reused once its stream ends.
+type StreamClient struct {
+	// UserID is the authenticated user, stored under "user_id", or nil
+	UserID any
+
+	keys map[string]any
+}
+
+// Get returns a value the request's middleware stored in its Context
+func (sc *StreamClient) Get(key string) (any, bool) {
+	value, exists := sc.keys[key]
+	return value, exists
+}
+
+// newStreamClient captures the values stored in a request's Context
+func newStreamClient(c *Context) *StreamClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sc := &StreamClient{keys: make(map[string]any, len(c.keys))}
+	for key, value := range c.keys {
+		sc.keys[key] = value
+	}
+	sc.UserID = sc.keys["user_id"]
+	return sc
+}
+
+// emitterClient is a connected client of an EmitterStream
+type emitterClient struct {
+	info    *StreamClient
+	events  map[string]bool
+	queue   chan SSEvent
+	dropped chan struct{}
+	once    sync.Once
+}
+
+// NewEmitterStream subscribes to the given events of an emitter. Close
+// unsubscribes and ends the connected streams.
+func NewEmitterStream(em *emitter.Emitter, events []string, opts ...StreamOption) *EmitterStream {
+	cfg := &streamConfig{heartbeat: DefaultSSEHeartbeat, history: defaultStreamHistory}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	es := &EmitterStream{
+		events:  events,
+		cfg:     cfg,
+		opts:    opts,
+		closed:  make(chan struct{}),
+		clients: make(map[*emitterClient]struct{}),
+	}
+	for _, event := range events {
+		event := event
+		es.unsubscribe = append(es.unsubscribe, em.Subscribe(event, func(data any) {
+			es.publish(event, data)
+		}))
+	}
+	return es
+}
+
+// Handler returns the handler streaming the events to a client
+func (es *EmitterStream) Handler() HandlerFunc {
+	return func(c *Context) error {
+		selected, err := es.selectEvents(c.Query("events"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+
+		client := &emitterClient{
+			info:    newStreamClient(c),
+			events:  selected,
+			queue:   make(chan SSEvent, emitterClientBuffer),
+			dropped: make(chan struct{}),
+		}
+
+		return c.Stream(func(stream *SSEStream) error {
+			missed := es.register(client, stream.LastEventID())
+			defer es.unregister(client)
+
+			for _, event := range missed {
+				if err := stream.Send(event); err != nil {
+					return err
+				}
+			}
+
+			for {
+				select {
+				case event := <-client.queue:
+					if err := stream.Send(event); err != nil {
+						return err
+					}
+				case <-client.dropped:
+					// Too slow; the client reconnects and replays from its last event
+					return nil
+				case <-es.closed:
+					return nil
+				case <-stream.Done():
+					return nil
+				}
+			}
+		}, es.opts...)
+	}
+}
+
+// Clients returns the number of connected clients
+func (es *EmitterStream) Clients() int {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return len(es.clients)
+}
+
+// Close unsubscribes from the emitter and ends the connected streams
+func (es *EmitterStream) Close() {
+	es.closeOnce.Do(func() {
+		for _, unsubscribe := range es.unsubscribe {
+			unsubscribe()
+		}
+		close(es.closed)
+	})
+}
+
+// publish records an event and queues it for the clients that want it
+func (es *EmitterStream) publish(name string, data any) {
+	es.mu.Lock()
+	es.seq++
+	relayed := emitterEvent{
+		seq:   es.seq,
+		event: SSEvent{ID: strconv.FormatUint(es.seq, 10), Event: name, Data: data},
+	}
+	if es.cfg.history > 0 {
+		es.history = append(es.history, relayed)
+		if len(es.history) > es.cfg.history {
+			es.history = append([]emitterEvent(nil), es.history[len(es.history)-es.cfg.history:]...)
+		}
+	}
+	clients := make([]*emitterClient, 0, len(es.clients))
+	for client := range es.clients {
+		clients = append(clients, client)
+	}
+	es.mu.Unlock()
+
+	for _, client := range clients {
+		if !es.connected(client) || !es.accepts(client, relayed.event) {
+			continue
+		}
+		select {
+		case client.queue <- relayed.event:
+		default:
+			client.once.Do(func() { close(client.dropped) })
+		}
+	}
+}
+
+// register adds a client and returns the recorded events after lastEventID
+func (es *EmitterStream) register(client *emitterClient, lastEventID string) []SSEvent {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	es.clients[client] = struct{}{}
+
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || last >= es.seq {
+		return nil
+	}
+	var missed []SSEvent
+	for _, relayed := range es.history {
+		if relayed.seq > last && es.accepts(client, relayed.event) {
+			missed = append(missed, relayed.event)
+		}
+	}
+	return missed
+}
+
+// unregister removes a client
+func (es *EmitterStream) unregister(client *emitterClient) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	delete(es.clients, client)
+}
+
+// connected reports whether a client has not yet been unregistered
+func (es *EmitterStream) connected(client *emitterClient) bool {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	_, ok := es.clients[client]
+	return ok
+}
+
+// accepts reports whether a client receives an event
+func (es *EmitterStream) accepts(client *emitterClient, event SSEvent) bool {
+	if !client.events[event.Event] {
+		return false
+	}
+	return es.cfg.filter == nil || es.cfg.filter(client.info, event.Event, event.Data)
+}
+
+// selectEvents parses the events requested by a client, defaulting to all
+func (es *EmitterStream) selectEvents(query string) (map[string]bool, error) {
+	selected := make(map[string]bool, len(es.events))
+	if query == "" {
+		for _, event := range es.events {
+			selected[event] = true
+		}
+		return selected, nil
+	}
+
+	for _, event := range strings.Split(query, ",") {
+		event = strings.TrimSpace(event)
+		if event == "" {
+			continue
+		}
+		if !slices.Contains(es.events, event) {
+			return nil, fmt.Errorf("unknown event %q; available: %s", event, strings.Join(es.events, ", "))
+		}
+		selected[event] = true
+	}
+	return selected, nil
+}
@@ -0,0 +1,287 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache-Control of fingerprinted assets, whose URL changes with their content
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// fingerprintPattern matches file names whose last segment is a content
+// hash, e.g. index-B2xk9aQf.js (Vite) or main.3f2a1b9c8d7e.css (webpack)
+var fingerprintPattern = regexp.MustCompile(`[-.]([A-Za-z0-9_]{8,})$`)
+
+// StaticOption configures static file serving
+type StaticOption func(*staticConfig)
+
+// staticConfig holds the static file serving options
+type staticConfig struct {
+	index     string
+	maxAge    time.Duration
+	immutable func(name string) bool
+}
+
+// WithIndex sets the file served for directories, index.html by default
+func WithIndex(name string) StaticOption {
+	return func(cfg *staticConfig) {
+		cfg.index = name
+	}
+}
+
+// WithMaxAge sets how long clients may cache files that are neither HTML nor
+// fingerprinted; zero sends no Cache-Control
+func WithMaxAge(d time.Duration) StaticOption {
+	return func(cfg *staticConfig) {
+		cfg.maxAge = d
+	}
+}
+
+// WithImmutable sets the check deciding which files are fingerprinted and
+// cached for a year, e.g. Fingerprinted for a build output directory. By
+// default no file is.
+func WithImmutable(match func(name string) bool) StaticOption {
+	return func(cfg *staticConfig) {
+		cfg.immutable = match
+	}
+}
+
+// fileServer serves the files of an fs.FS
+type fileServer struct {
+	fsys  fs.FS
+	cfg   *staticConfig
+	etags sync.Map // name -> ETag of files without a modification time
+}
+
+// newFileServer applies the options
+func newFileServer(fsys fs.FS, opts []StaticOption) *fileServer {
+	cfg := &staticConfig{index: "index.html"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &fileServer{fsys: fsys, cfg: cfg}
+}
+
+// StaticFS serves the files of fsys under prefix, e.g. an embed.FS:
+//
+//	//go:embed all:public
+//	var public embed.FS
+//
+//	assets, _ := fs.Sub(public, "public")
+//	r.StaticFS("/", assets)
+//
+// Requests are confined to fsys. Range and conditional requests are
+// supported, and a precompressed name.gz next to a file is served to clients
+// accepting gzip. HTML files are sent with Cache-Control: no-cache, and files
+// matched by WithImmutable as immutable:
+//
+//	r.StaticFS("/assets", viteAssets, router.WithImmutable(router.Fingerprinted))
+func (r *Router) StaticFS(prefix string, fsys fs.FS, opts ...StaticOption) {
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	server := newFileServer(fsys, opts)
+	handler := func(c *Context) error {
+		return server.serve(c, strings.TrimPrefix(c.Request.URL.Path, prefix), false)
+	}
+
+	r.GET(prefix+"/*filepath", handler)
+	if prefix != "" {
+		r.GET(prefix, handler) // also serve the exact prefix URL
+	}
+}
+
+// SPAHandler serves a single page application from fsys: existing files are
+// served like StaticFS does, other paths without a file extension get the
+// index file so that the client-side router can handle them. Missing assets
+// are still 404s.
+//
+//	app.router.NotFound(router.SPAHandler(assets))
+func SPAHandler(fsys fs.FS, opts ...StaticOption) HandlerFunc {
+	server := newFileServer(fsys, opts)
+	return func(c *Context) error {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return defaultNotFound(c)
+		}
+		return server.serve(c, c.Request.URL.Path, true)
+	}
+}
+
+// DevProxy returns a handler forwarding requests to a development server,
+// e.g. Vite, including its hot reload WebSocket
+func DevProxy(target string) (HandlerFunc, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dev server URL %q: %w", target, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid dev server URL %q: scheme and host are required", target)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, "dev server unavailable: "+err.Error(), http.StatusBadGateway)
+	}
+	return func(c *Context) error {
+		proxy.ServeHTTP(c.Writer, c.Request)
+		return nil
+	}, nil
+}
+
+// serve sends the file for a request path. With spa set, paths that match no
+// file and have no extension get the index file.
+func (s *fileServer) serve(c *Context, urlPath string, spa bool) error {
+	// Cleaning a rooted path drops every "..", so requests stay inside fsys
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if strings.ContainsAny(name, "\\\x00") {
+		return defaultNotFound(c)
+	}
+	if name == "" {
+		name = "."
+	}
+	requested := name
+
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		name = path.Join(name, s.cfg.index)
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil || info.IsDir() {
+		if !spa || path.Ext(requested) != "" {
+			return defaultNotFound(c)
+		}
+		name = s.cfg.index
+		if info, err = fs.Stat(s.fsys, name); err != nil || info.IsDir() {
+			return defaultNotFound(c)
+		}
+	}
+
+	return s.serveFile(c, name, info)
+}
+
+// serveFile sends a file, or its precompressed sibling
+func (s *fileServer) serveFile(c *Context, name string, info fs.FileInfo) error {
+	header := c.Writer.Header()
+	if cacheControl := s.cacheControl(name); cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	served := name
+	if gz, err := fs.Stat(s.fsys, name+".gz"); err == nil && !gz.IsDir() {
+		AddVary(header, "Accept-Encoding")
+		if EncodingQuality(c.Header("Accept-Encoding"), "gzip") > 0 {
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			header.Set("Content-Encoding", "gzip")
+			served, info = name+".gz", gz
+		}
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	file, err := s.fsys.Open(served)
+	if err != nil {
+		return defaultNotFound(c)
+	}
+	defer file.Close()
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(data)
+	}
+
+	modTime := info.ModTime()
+	if modTime.IsZero() && header.Get("ETag") == "" {
+		// Embedded files have no modification time; validate by content
+		etag, err := s.etag(served, content)
+		if err != nil {
+			return err
+		}
+		header.Set("ETag", etag)
+	}
+
+	http.ServeContent(c.Writer, c.Request, name, modTime, content)
+	return nil
+}
+
+// etag returns the cached content hash of a file without a modification
+// time, which does not change while the program runs
+func (s *fileServer) etag(name string, content io.ReadSeeker) (string, error) {
+	if etag, ok := s.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.Store(name, etag)
+	return etag, nil
+}
+
+// cacheControl returns the Cache-Control header of a file: HTML is always
+// revalidated so deployments take effect, fingerprinted assets never are
+func (s *fileServer) cacheControl(name string) string {
+	switch {
+	case path.Ext(name) == ".html":
+		return "no-cache"
+	case s.cfg.immutable != nil && s.cfg.immutable(name):
+		return immutableCacheControl
+	case s.cfg.maxAge > 0:
+		return "public, max-age=" + strconv.Itoa(int(s.cfg.maxAge.Seconds()))
+	}
+	return ""
+}
+
+// Fingerprinted reports whether a file name ends in a content hash. Hashes
+// are told apart from words by containing a digit or an upper case letter.
+// Only use it where build tools write hashed names, as a public file such as
+// logo-1024x1024.png would match too.
+func Fingerprinted(name string) bool {
+	base := path.Base(name)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	match := fingerprintPattern.FindStringSubmatch(stem)
+	if match == nil {
+		return false
+	}
+	return strings.ContainsAny(match[1], "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// AddVary adds a field to the Vary header unless it is listed already
+func AddVary(header http.Header, field string) {
+	for _, value := range header.Values("Vary") {
+		for _, existing := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), field) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", field)
+}
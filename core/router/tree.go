@@ -1,14 +1,23 @@
 package router
 
-// node represents a node in the routing tree
+import (
+	"strings"
+)
+
+// node represents a path segment in the routing tree. Routes are matched one
+// segment at a time: static segments first, then params (constrained params
+// before unconstrained ones) and finally a catch-all. A branch that does not
+// lead to a handler is abandoned for the next candidate, so static segments,
+// params and catch-alls can share a level.
 type node struct {
-	path      string
-	indices   string
-	wildChild bool
-	nType     nodeType
-	priority  uint32
-	children  []*node
-	handler   HandlerFunc
+	segment    string // static text, or the wildcard as registered, e.g. ":id<uint>"
+	nType      nodeType
+	key        string           // name of a param or catch-all
+	constraint *paramConstraint // constraint of a param, if any
+	children   []*node          // static children
+	params     []*node          // param children, constrained ones first
+	catchAll   *node
+	handler    HandlerFunc
 }
 
 type nodeType uint8
@@ -23,360 +32,194 @@ const (
 // addRoute adds a route to the tree
 func (n *node) addRoute(path string, handler HandlerFunc) {
 	fullPath := path
-	n.priority++
-
-	// Empty tree
-	if n.path == "" && n.children == nil {
-		n.insertChild(path, fullPath, handler)
-		n.nType = root
-		return
-	}
-
-walk:
-	for {
-		// Find the longest common prefix
-		i := longestCommonPrefix(path, n.path)
-
-		// Split edge
-		if i < len(n.path) {
-			child := node{
-				path:      n.path[i:],
-				wildChild: n.wildChild,
-				nType:     static,
-				indices:   n.indices,
-				children:  n.children,
-				handler:   n.handler,
-				priority:  n.priority - 1,
-			}
-
-			n.children = []*node{&child}
-			n.indices = string([]byte{n.path[i]})
-			n.path = path[:i]
-			n.handler = nil
-			n.wildChild = false
-		}
-
-		// Make new node a child of this node
-		if i < len(path) {
-			path = path[i:]
+	n.nType = root
 
-			if n.wildChild {
-				n = n.children[len(n.children)-1]
-				n.priority++
-
-				// Check if the wildcard matches
-				if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
-					(len(n.path) >= len(path) || path[len(n.path)] == '/') {
-					continue walk
-				} else {
-					panic("path segment '" + path +
-						"' conflicts with existing wildcard '" + n.path +
-						"' in path '" + fullPath + "'")
-				}
-			}
-
-			idxc := path[0]
-
-			// '/' after param
-			if n.nType == param && idxc == '/' && len(n.children) == 1 {
-				n = n.children[0]
-				n.priority++
-				continue walk
-			}
-
-			// Check if a child with the next path byte exists
-			for i, c := range []byte(n.indices) {
-				if c == idxc {
-					n = n.children[i]
-					n.priority++
-					continue walk
-				}
+	segments := splitPattern(path[1:])
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, "*"):
+			if i != len(segments)-1 {
+				panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
 			}
-
-			// Otherwise insert it
-			if idxc != ':' && idxc != '*' {
-				// []byte for proper unicode char conversion
-				n.indices += string([]byte{idxc})
-				child := &node{}
-				n.children = append(n.children, child)
-				n = child
+			n = n.addCatchAll(segment, fullPath)
+		case strings.HasPrefix(segment, ":"):
+			n = n.addParam(segment, fullPath)
+		default:
+			if strings.ContainsAny(segment, ":*") {
+				panic("a wildcard must make up a whole path segment, has: '" +
+					segment + "' in path '" + fullPath + "'")
 			}
-			n.insertChild(path, fullPath, handler)
-			return
+			n = n.addStatic(segment)
 		}
+	}
 
-		// Otherwise add handle to current node
-		if n.handler != nil {
-			panic("a handler is already registered for path '" + fullPath + "'")
-		}
-		n.handler = handler
-		return
+	if n.handler != nil {
+		panic("a handler is already registered for path '" + fullPath + "'")
 	}
+	n.handler = handler
 }
 
-// insertChild inserts a new child node
-func (n *node) insertChild(path, fullPath string, handler HandlerFunc) {
-	for {
-		// Find wildcard segment
-		wildcard, i, valid := findWildcard(path)
-		if i < 0 {
-			break
+// addStatic returns the static child for a segment, adding it if needed
+func (n *node) addStatic(segment string) *node {
+	for _, child := range n.children {
+		if child.segment == segment {
+			return child
 		}
+	}
+	child := &node{segment: segment, nType: static}
+	n.children = append(n.children, child)
+	return child
+}
 
-		// The wildcard name must not contain ':' and '*'
-		if !valid {
-			panic("only one wildcard per path segment is allowed, has: '" +
-				wildcard + "' in path '" + fullPath + "'")
+// addParam returns the param child for a segment, adding it if needed.
+// Params with the same name and constraint share a node.
+func (n *node) addParam(segment, fullPath string) *node {
+	for _, child := range n.params {
+		if child.segment == segment {
+			return child
 		}
+	}
 
-		// Check if the wildcard has a name
-		if len(wildcard) < 2 {
-			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
-		}
+	name, expr := splitWildcard(segment)
+	if name == "" {
+		panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+	}
+	if strings.ContainsAny(name, ":*<>") {
+		panic("only one wildcard per path segment is allowed, has: '" +
+			segment + "' in path '" + fullPath + "'")
+	}
 
-		// Check if this node has existing children which would be unreachable
-		// Allow wildcard routes alongside static children if the wildcard comes after static routes
-		if len(n.children) > 0 && wildcard[0] == '*' {
-			// Catch-all wildcards (*) conflict with existing children
-			panic("catch-all segment '" + wildcard +
-				"' conflicts with existing children in path '" + fullPath + "'")
+	child := &node{segment: segment, nType: param, key: name}
+	if expr != "" {
+		constraint, err := compileConstraint(expr)
+		if err != nil {
+			panic("invalid constraint of '" + segment + "' in path '" + fullPath + "': " + err.Error())
 		}
+		child.constraint = constraint
 
-		if wildcard[0] == ':' { // param
-			if i > 0 {
-				// Insert prefix before the current wildcard
-				n.path = path[:i]
-				path = path[i:]
-			}
-
-			n.wildChild = true
-			child := &node{
-				nType: param,
-				path:  wildcard,
-			}
-			n.children = append(n.children, child)
-			n = child
-
-			// If the path doesn't end with the wildcard, then there will be
-			// another non-wildcard subpath starting with '/'
-			if len(wildcard) < len(path) {
-				path = path[len(wildcard):]
-				child := &node{
-					priority: 1,
-				}
-				n.children = []*node{child}
-				n = child
-				continue
-			}
-
-			// Otherwise we're done. Insert the handler
-			n.handler = handler
-			return
-
-		} else { // catchAll
-			if i+len(wildcard) != len(path) {
-				panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
-			}
-
-			if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
-				panic("catch-all conflicts with existing handler for the path segment root in path '" + fullPath + "'")
-			}
-
-			// Currently fixed width 1 for '/'
-			i--
-			if path[i] != '/' {
-				panic("no / before catch-all in path '" + fullPath + "'")
-			}
-
-			n.path = path[:i]
-
-			// First node: catchAll node with empty path
-			child := &node{
-				wildChild: true,
-				nType:     catchAll,
-			}
-			n.children = []*node{child}
-			n.indices = string('/')
-			n = child
-
-			// Second node: node holding the variable
-			child = &node{
-				path:     path[i:],
-				nType:    catchAll,
-				handler:  handler,
-				priority: 1,
-			}
-			n.children = []*node{child}
-
-			return
+		// Constrained params are tried before unconstrained ones
+		i := 0
+		for i < len(n.params) && n.params[i].constraint != nil {
+			i++
 		}
+		n.params = append(n.params[:i], append([]*node{child}, n.params[i:]...)...)
+		return child
 	}
 
-	// If no wildcard was found, simply insert the path and handler
-	n.path = path
-	n.handler = handler
+	n.params = append(n.params, child)
+	return child
 }
 
-// getValue returns the handler for the given path
-func (n *node) getValue(path string) (handler HandlerFunc, params Params, tsr bool) {
-walk: // Outer loop for walking the tree
-	for {
-		prefix := n.path
-		if len(path) > len(prefix) {
-			if path[:len(prefix)] == prefix {
-				path = path[len(prefix):]
-
-				// Always try static routes first, even if wildcard child exists
-				idxc := path[0]
-				for i, c := range []byte(n.indices) {
-					if c == idxc {
-						n = n.children[i]
-						continue walk
-					}
-				}
-
-				// If no static route found and wildcard child exists, try wildcard
-				if n.wildChild {
-					// Handle wildcard child (now always the last child)
-					n = n.children[len(n.children)-1]
-				} else {
-					// Nothing found
-					tsr = (path == "/" && n.handler != nil)
-					return
-				}
-				switch n.nType {
-				case param:
-					// Find param end
-					end := 0
-					for end < len(path) && path[end] != '/' {
-						end++
-					}
-
-					// Save param value
-					if params == nil {
-						params = make(Params, 0, 4)
-					}
-					params = append(params, Param{
-						Key:   n.path[1:],
-						Value: path[:end],
-					})
+// addCatchAll returns the catch-all child of a node, adding it if needed
+func (n *node) addCatchAll(segment, fullPath string) *node {
+	name := segment[1:]
+	if name == "" {
+		panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+	}
+	if strings.ContainsAny(name, ":*<>") {
+		panic("only one wildcard per path segment is allowed, has: '" +
+			segment + "' in path '" + fullPath + "'")
+	}
 
-					// We need to go deeper!
-					if end < len(path) {
-						if len(n.children) > 0 {
-							path = path[end:]
-							n = n.children[0]
-							continue walk
-						}
+	if n.catchAll != nil {
+		if n.catchAll.key != name {
+			panic("catch-all segment '" + segment + "' conflicts with existing catch-all '" +
+				n.catchAll.segment + "' in path '" + fullPath + "'")
+		}
+		return n.catchAll
+	}
+	n.catchAll = &node{segment: segment, nType: catchAll, key: name}
+	return n.catchAll
+}
 
-						// ... but we can't
-						tsr = (len(path) == end+1)
-						return
-					}
+// getValue returns the handler and params for the given path
+func (n *node) getValue(path string) (HandlerFunc, Params) {
+	if path == "" || path[0] != '/' {
+		return nil, nil
+	}
 
-					if handler = n.handler; handler != nil {
-						return
-					}
-					if len(n.children) == 1 {
-						// No handler found. Check if a handler for this path + a
-						// trailing slash exists for TSR recommendation
-						n = n.children[0]
-						tsr = (n.path == "/" && n.handler != nil) || (n.path == "" && n.indices == "/")
-					}
-					return
+	var params Params
+	handler := n.match(path[1:], &params)
+	if handler == nil {
+		return nil, nil
+	}
+	return handler, params
+}
 
-				case catchAll:
-					// Save param value
-					if params == nil {
-						params = make(Params, 0, 4)
-					}
-					params = append(params, Param{
-						Key:   n.path[2:],
-						Value: path,
-					})
+// match finds the handler for the rest of a path below the node, collecting
+// the values of the params it passes
+func (n *node) match(path string, params *Params) HandlerFunc {
+	segment, rest, last := path, "", true
+	if end := strings.IndexByte(path, '/'); end >= 0 {
+		segment, rest, last = path[:end], path[end+1:], false
+	}
 
-					handler = n.handler
-					return
+	next := func(child *node) HandlerFunc {
+		if last {
+			return child.handler
+		}
+		return child.match(rest, params)
+	}
 
-				default:
-					panic("invalid node type")
-				}
-			}
-		} else if path == prefix {
-			// We should have reached the node containing the handler
-			if handler = n.handler; handler != nil {
-				return
+	for _, child := range n.children {
+		if child.segment == segment {
+			if handler := next(child); handler != nil {
+				return handler
 			}
+			break
+		}
+	}
 
-			// If there is no handler for this route, but this route has a
-			// wildcard child, there must be a handler for this path with an
-			// additional trailing slash
-			if path == "/" && n.wildChild && n.nType != root {
-				tsr = true
-				return
+	if segment != "" {
+		for _, child := range n.params {
+			if child.constraint != nil && !child.constraint.match(segment) {
+				continue
 			}
-
-			// No handler found. Check if a handler for this path + a
-			// trailing slash exists for trailing slash recommendation
-			for i, index := range []byte(n.indices) {
-				if index == '/' {
-					n = n.children[i]
-					tsr = (len(n.path) == 1 && n.handler != nil) ||
-						(n.nType == catchAll && n.children[0].handler != nil)
-					return
-				}
+			*params = append(*params, Param{Key: child.key, Value: segment})
+			if handler := next(child); handler != nil {
+				return handler
 			}
-
-			return
+			*params = (*params)[:len(*params)-1]
 		}
+	}
 
-		// Nothing found. We can recommend to redirect to the same URL
-		// without a trailing slash if a leaf exists for that path
-		tsr = (path == "/") ||
-			(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
-				path == prefix[:len(prefix)-1] && n.handler != nil)
-		return
+	if n.catchAll != nil {
+		*params = append(*params, Param{Key: n.catchAll.key, Value: "/" + path})
+		return n.catchAll.handler
 	}
+	return nil
 }
 
-// findWildcard finds a wildcard segment in the path
-func findWildcard(path string) (wildcard string, i int, valid bool) {
-	// Find start
-	for start, c := range []byte(path) {
-		// A wildcard starts with ':' (param) or '*' (catch-all)
-		if c != ':' && c != '*' {
-			continue
-		}
-
-		// Find end and check for invalid characters
-		valid = true
-		for end, c := range []byte(path[start+1:]) {
-			switch c {
-			case '/':
-				return path[start : start+1+end], start, valid
-			case ':', '*':
-				valid = false
+// splitPattern splits a route pattern into segments at the slashes outside
+// of constraints, so that a constraint containing one is reported rather than
+// cut in two
+func splitPattern(pattern string) []string {
+	var segments []string
+	depth, start := 0, 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '<':
+			depth++
+		case '>':
+			if depth > 0 {
+				depth--
+			}
+		case '/':
+			if depth == 0 {
+				segments = append(segments, pattern[start:i])
+				start = i + 1
 			}
 		}
-		return path[start:], start, valid
-	}
-	return "", -1, false
-}
-
-// longestCommonPrefix finds the longest common prefix
-func longestCommonPrefix(a, b string) int {
-	i := 0
-	max := min(len(a), len(b))
-	for i < max && a[i] == b[i] {
-		i++
 	}
-	return i
+	return append(segments, pattern[start:])
 }
 
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a <= b {
-		return a
+// splitWildcard splits a wildcard segment into its name and constraint, e.g.
+// ":id<uint>" into "id" and "uint"
+func splitWildcard(segment string) (name, constraint string) {
+	name = segment[1:]
+	if open := strings.IndexByte(name, '<'); open >= 0 && strings.HasSuffix(name, ">") {
+		return name[:open], name[open+1 : len(name)-1]
 	}
-	return b
+	return name, ""
 }
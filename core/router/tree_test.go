@@ -0,0 +1,169 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// routeHandler returns a handler responding with its route pattern and the
+// params it matched
+func routeHandler(pattern string) HandlerFunc {
+	return func(c *Context) error {
+		var params []string
+		for _, p := range c.params {
+			params = append(params, p.Key+"="+p.Value)
+		}
+		return c.String(http.StatusOK, "%s %s", pattern, strings.Join(params, ","))
+	}
+}
+
+func TestTreeMatch(t *testing.T) {
+	patterns := []string{
+		"/",
+		"/users",
+		"/users/new",
+		"/users/:id<uint>",
+		"/users/:id<uint>/posts",
+		"/users/:name",
+		"/users/:name/profile",
+		"/users/me/settings",
+		"/files/*path",
+		"/files/readme",
+		"/docs/:section/*rest",
+		"/items/:slug<slug>/edit",
+		"/items/:id/view",
+		"/tags/:tag<[a-z]+>",
+	}
+
+	tree := &node{}
+	for _, pattern := range patterns {
+		tree.addRoute(pattern, routeHandler(pattern))
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string // "" for no match
+	}{
+		{"root", "/", "/ "},
+		{"static", "/users", "/users "},
+		{"static before params", "/users/new", "/users/new "},
+		{"constrained param", "/users/42", "/users/:id<uint> id=42"},
+		{"unconstrained param when constraint fails", "/users/alice", "/users/:name name=alice"},
+		{"constrained param subtree", "/users/42/posts", "/users/:id<uint>/posts id=42"},
+		{"backtrack from constrained param", "/users/42/profile", "/users/:name/profile name=42"},
+		{"backtrack from static", "/users/me/profile", "/users/:name/profile name=me"},
+		{"static subtree", "/users/me/settings", "/users/me/settings "},
+		{"catch-all", "/files/a/b/c.txt", "/files/*path path=/a/b/c.txt"},
+		{"static before catch-all", "/files/readme", "/files/readme "},
+		{"catch-all of one segment", "/files/other", "/files/*path path=/other"},
+		{"param and catch-all", "/docs/api/v1/intro", "/docs/:section/*rest section=api,rest=/v1/intro"},
+		{"registered constraint", "/items/my-item/edit", "/items/:slug<slug>/edit slug=my-item"},
+		{"backtrack to param sibling", "/items/my-item/view", "/items/:id/view id=my-item"},
+		{"constraint fails", "/items/My_Item/edit", ""},
+		{"regexp constraint", "/tags/go", "/tags/:tag<[a-z]+> tag=go"},
+		{"regexp constraint fails", "/tags/Go1", ""},
+		{"unknown static", "/unknown", ""},
+		{"too deep", "/users/42/posts/1", ""},
+		{"empty param", "/users//posts", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, params := tree.getValue(tt.path)
+			if tt.want == "" {
+				if handler != nil {
+					t.Fatalf("getValue(%q) matched, want no match", tt.path)
+				}
+				return
+			}
+			if handler == nil {
+				t.Fatalf("getValue(%q) did not match, want %q", tt.path, tt.want)
+			}
+
+			rec := httptest.NewRecorder()
+			c := &Context{}
+			c.reset(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			c.params = params
+			if err := handler(c); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("getValue(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterTrailingSlash(t *testing.T) {
+	r := New()
+	for _, pattern := range []string{"/", "/users", "/users/:id", "/files/*path"} {
+		r.GET(pattern, routeHandler(pattern))
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/", http.StatusOK, "/ "},
+		{"/users/", http.StatusOK, "/users "},
+		{"/users/42/", http.StatusOK, "/users/:id id=42"},
+		{"/files/a/", http.StatusOK, "/files/*path path=/a"},
+		{"/files/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("GET %s = %d, want %d", tt.path, rec.Code, tt.status)
+			}
+			if tt.want != "" && rec.Body.String() != tt.want {
+				t.Errorf("GET %s = %q, want %q", tt.path, rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRouteConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		panics   string // "" when registration succeeds
+	}{
+		{"param siblings", []string{"/a/:id<uint>", "/a/:name"}, ""},
+		{"same param twice", []string{"/a/:id", "/a/:id"}, "already registered"},
+		{"catch-all not last", []string{"/a/*path/b"}, "only allowed at the end"},
+		{"conflicting catch-alls", []string{"/a/*path", "/a/*rest"}, "conflicts with existing catch-all"},
+		{"unnamed param", []string{"/a/:"}, "non-empty name"},
+		{"wildcard inside segment", []string{"/a/b:id"}, "whole path segment"},
+		{"invalid constraint", []string{"/a/:id<[>"}, "invalid constraint"},
+		{"constraint with a slash", []string{"/a/:path<[a-z/]+>"}, "contains '/'"},
+		{"constraint with a slash before more segments", []string{"/a/:path<x/y>/b"}, "contains '/'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				p := recover()
+				switch {
+				case tt.panics == "" && p != nil:
+					t.Fatalf("unexpected panic: %v", p)
+				case tt.panics != "" && p == nil:
+					t.Fatalf("no panic, want one containing %q", tt.panics)
+				case tt.panics != "" && !strings.Contains(p.(string), tt.panics):
+					t.Fatalf("panic %q, want one containing %q", p, tt.panics)
+				}
+			}()
+
+			tree := &node{}
+			for _, pattern := range tt.patterns {
+				tree.addRoute(pattern, routeHandler(pattern))
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	baseerrors "base/core/errors"
+	"base/core/validator"
+)
+
+// typedContextKey stores the router context in the request context passed to
+// typed handlers
+var typedContextKey = &struct{ name string }{"router.context"}
+
+// TypedOption configures a handler created by Typed
+type TypedOption func(*typedConfig)
+
+type typedConfig struct {
+	status int
+}
+
+// WithStatus sets the status code of successful responses. By default POST
+// responds with 201 Created, handlers returning no body (see NoBody) with
+// 204 No Content, and everything else with 200 OK.
+func WithStatus(status int) TypedOption {
+	return func(cfg *typedConfig) {
+		cfg.status = status
+	}
+}
+
+// NoBody is the response type of typed handlers that return no content
+type NoBody struct{}
+
+// typedError is the JSON body of error responses, matching types.ErrorResponse
+type typedError struct {
+	Error   string `json:"error"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code,omitempty"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Typed adapts a typed handler to a HandlerFunc. The request is bound into Req
+// from the body, route params, query string and headers (see Bind) and
+// validated with its `binding` tags; the response is written as JSON.
+//
+// Binding and validation errors respond with 400 and the validation errors as
+// details. Errors with an HTTPStatus method, such as *errors.Error, respond
+// with that status; any other error responds with 500.
+//
+//	group.POST("/posts", router.Typed(func(ctx context.Context, req CreatePostRequest) (*Post, error) {
+//		return service.Create(ctx, req)
+//	}))
+func Typed[Req, Resp any](handler func(ctx context.Context, req Req) (Resp, error), opts ...TypedOption) HandlerFunc {
+	cfg := &typedConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	_, noBody := any(*new(Resp)).(NoBody)
+
+	reqType := reflect.TypeOf((*Req)(nil)).Elem()
+
+	return func(c *Context) error {
+		var req Req
+		target := any(&req)
+		if reqType.Kind() == reflect.Pointer {
+			// Pointer requests are allocated and bound in place
+			req = reflect.New(reqType.Elem()).Interface().(Req)
+			target = req
+		}
+		if err := c.ShouldBind(target); err != nil {
+			return writeBindError(c, err)
+		}
+
+		ctx := context.WithValue(c.Request.Context(), typedContextKey, c)
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return writeTypedError(c, err)
+		}
+
+		status := cfg.status
+		if status == 0 {
+			switch {
+			case noBody:
+				status = http.StatusNoContent
+			case c.Request.Method == http.MethodPost:
+				status = http.StatusCreated
+			default:
+				status = http.StatusOK
+			}
+		}
+
+		if noBody || status == http.StatusNoContent {
+			c.Writer.WriteHeader(status)
+			return nil
+		}
+		return c.JSON(status, resp)
+	}
+}
+
+// FromContext returns the router context of a request handled by a typed
+// handler, e.g. to read values stored by middleware
+func FromContext(ctx context.Context) (*Context, bool) {
+	c, ok := ctx.Value(typedContextKey).(*Context)
+	return c, ok
+}
+
+// writeBindError responds to a request that could not be bound or validated
+func writeBindError(c *Context, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return writeBodyTooLarge(c, maxBytesErr.Limit)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return c.JSON(http.StatusBadRequest, typedError{
+			Error:   validationErrs.Error(),
+			Code:    int(baseerrors.CodeValidation),
+			Details: validationErrs,
+		})
+	}
+	return c.JSON(http.StatusBadRequest, typedError{
+		Error: err.Error(),
+		Code:  int(baseerrors.CodeBadRequest),
+	})
+}
+
+// writeTypedError maps a handler error to a status code and writes it
+func writeTypedError(c *Context, err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return writeBindError(c, err)
+	}
+
+	var baseErr *baseerrors.Error
+	if errors.As(err, &baseErr) {
+		status := baseErr.HTTPStatus()
+		body := typedError{Error: baseErr.Error(), Code: int(baseErr.Code)}
+		if status >= http.StatusInternalServerError {
+			// Details may describe internals; they are returned to be logged
+			body.Error = baseErr.Message
+			return respondAndReturn(c, status, body, err)
+		}
+		if len(baseErr.Metadata) > 0 {
+			body.Details = baseErr.Metadata
+		}
+		return c.JSON(status, body)
+	}
+
+	var statusErr interface {
+		error
+		HTTPStatus() int
+	}
+	if errors.As(err, &statusErr) {
+		status := statusErr.HTTPStatus()
+		if status >= http.StatusInternalServerError {
+			message := strings.ToLower(http.StatusText(status))
+			if message == "" {
+				message = "internal server error"
+			}
+			return respondAndReturn(c, status, typedError{Error: message}, err)
+		}
+		// Wrapping errors may add context meant for logs, not clients
+		return c.JSON(status, typedError{Error: statusErr.Error()})
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return writeBodyTooLarge(c, maxBytesErr.Limit)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return c.JSON(http.StatusGatewayTimeout, typedError{Error: "request timed out"})
+	}
+
+	// Other errors, e.g. from GORM, may describe queries or paths: the client
+	// gets a generic message and the error is returned to be logged
+	return respondAndReturn(c, http.StatusInternalServerError, typedError{Error: "internal server error"}, err)
+}
+
+// respondAndReturn writes an error response and returns err, for logging
+// middleware; the router does not respond again once a response is written
+func respondAndReturn(c *Context, status int, body typedError, err error) error {
+	if writeErr := c.JSON(status, body); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
@@ -0,0 +1,459 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// VersionHeader is the request header selecting an API version
+const VersionHeader = "Accept-Version"
+
+// vendorVersionPattern matches a vendor media type carrying a version, e.g.
+// application/vnd.base.v2+json
+var vendorVersionPattern = regexp.MustCompile(`^[a-z]+/vnd\.[a-z0-9.-]*?\.?v(\d+(?:\.\d+)*)(?:\+[a-z0-9.-]+)?$`)
+
+// apiVersion is a parsed version such as 2 or 2.1
+type apiVersion []int
+
+// parseVersion parses "2", "v2" or "2.1"
+func parseVersion(s string) (apiVersion, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	if s == "" {
+		return nil, false
+	}
+
+	var version apiVersion
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version = append(version, n)
+	}
+	return version, true
+}
+
+// compare orders versions; missing minor parts count as zero
+func (v apiVersion) compare(other apiVersion) int {
+	for i := 0; i < len(v) || i < len(other); i++ {
+		var a, b int
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(other) {
+			b = other[i]
+		}
+		if a != b {
+			return a - b
+		}
+	}
+	return 0
+}
+
+// versionedRoute holds the handlers of a method and path registered in
+// several versions, ordered by version
+type versionedRoute struct {
+	router   *Router
+	handlers []versionedHandler
+}
+
+// versionedHandler is the handler of one version of a route
+type versionedHandler struct {
+	name    string // as registered, e.g. "2"
+	version apiVersion
+	handler HandlerFunc
+}
+
+// add registers the handler of a version; r.mu must be held
+func (vr *versionedRoute) add(name string, handler HandlerFunc, path string) {
+	version, _ := parseVersion(name)
+	for _, h := range vr.handlers {
+		if h.version.compare(version) == 0 {
+			panic(fmt.Sprintf("version %s of route '%s' is already registered", name, path))
+		}
+	}
+	vr.handlers = append(vr.handlers, versionedHandler{name: name, version: version, handler: handler})
+	sort.Slice(vr.handlers, func(i, j int) bool {
+		return vr.handlers[i].version.compare(vr.handlers[j].version) < 0
+	})
+}
+
+// pick returns the handler of the highest version not above the requested
+// one, or of the lowest version when none was requested
+func (vr *versionedRoute) pick(requested apiVersion) (versionedHandler, bool) {
+	vr.router.mu.RLock()
+	defer vr.router.mu.RUnlock()
+
+	if requested == nil {
+		return vr.handlers[0], true
+	}
+	for i := len(vr.handlers) - 1; i >= 0; i-- {
+		if vr.handlers[i].version.compare(requested) <= 0 {
+			return vr.handlers[i], true
+		}
+	}
+	return versionedHandler{}, false
+}
+
+// serve dispatches a request to the version it asks for
+func (vr *versionedRoute) serve(c *Context) error {
+	requested, source := c.requestedVersion()
+	if source != "" && requested == nil {
+		return c.JSON(http.StatusBadRequest, typedError{Error: "invalid API version"})
+	}
+
+	// Unless the path names the version, the response depends on headers
+	header := c.Writer.Header()
+	if source != "path" {
+		AddVary(header, VersionHeader)
+		AddVary(header, "Accept")
+	}
+
+	h, ok := vr.pick(requested)
+	if !ok {
+		return c.JSON(http.StatusNotFound, typedError{
+			Error: "API version " + c.requestedVersionName() + " is not available",
+		})
+	}
+	c.version = h.name
+	header.Set("API-Version", h.name)
+	return h.handler(c)
+}
+
+// requestedVersion returns the version a request asks for, from the path,
+// the Accept-Version header or the Accept media type, in that order. The
+// version is nil if it is missing or invalid; source is empty if missing.
+func (c *Context) requestedVersion() (apiVersion, string) {
+	name, source := c.requestedVersionName(), ""
+	switch {
+	case c.pathVersion != "":
+		source = "path"
+	case c.Header(VersionHeader) != "":
+		source = "header"
+	case name != "":
+		source = "media type"
+	default:
+		return nil, ""
+	}
+	version, _ := parseVersion(name)
+	return version, source
+}
+
+// requestedVersionName returns the version a request asks for as sent
+func (c *Context) requestedVersionName() string {
+	if c.pathVersion != "" {
+		return c.pathVersion
+	}
+	if version := c.Header(VersionHeader); version != "" {
+		return version
+	}
+	return acceptVersion(c.Header("Accept"))
+}
+
+// acceptVersion returns the version of the first media range of an Accept
+// header that carries one, as a vendor media type or a version parameter of
+// a JSON or vendor media type. Ranges with a version that does not parse
+// are skipped, so parameters of other media types, such as the v=b3 that
+// browsers send with application/signed-exchange, never select a version.
+func acceptVersion(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if match := vendorVersionPattern.FindStringSubmatch(mediaType); match != nil {
+			return match[1]
+		}
+		if !versionedMediaType(mediaType) {
+			continue
+		}
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(key, "version") {
+				continue
+			}
+			value = strings.Trim(value, `"`)
+			if _, ok := parseVersion(value); ok {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// versionedMediaType reports whether a media type may carry an API version
+// parameter: application/json, a +json suffix or a vendor type
+func versionedMediaType(mediaType string) bool {
+	return mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasPrefix(mediaType, "application/vnd.")
+}
+
+// Version returns the version of the route serving the request, e.g. "2",
+// or an empty string for routes that are not versioned
+func (c *Context) Version() string {
+	return c.version
+}
+
+// Version returns a group registering the routes of an API version. A path
+// and method may be registered in several versions; requests select one by
+// a version segment after the group prefix (/api/v2/users), the
+// Accept-Version header or the Accept media type
+// (application/vnd.base.v2+json or application/json; version=2). The
+// highest registered version not above the requested one serves the
+// request, and requests without a version get the lowest.
+//
+//	api := r.Group("/api")
+//	api.Version("1").GET("/users", listUsers)
+//	api.Version("2").GET("/users", listUsersV2)
+//
+// Unversioned routes of the group are served for every version segment.
+func (g *RouterGroup) Version(version string) *RouterGroup {
+	if _, ok := parseVersion(version); !ok {
+		panic(fmt.Sprintf("invalid API version %q", version))
+	}
+	g.router.addVersionPrefix(g.host, g.prefix)
+
+	group := *g
+	group.version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	group.middleware = append([]MiddlewareFunc(nil), g.middleware...)
+	return &group
+}
+
+// addVersionPrefix registers a path prefix a version segment may follow
+func (r *Router) addVersionPrefix(host, prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	set := &r.routeSet
+	if host != "" {
+		set = &r.hostByPattern(host).routeSet
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	for _, existing := range set.versionPrefixes {
+		if existing == prefix {
+			return
+		}
+	}
+	set.versionPrefixes = append(set.versionPrefixes, prefix)
+}
+
+// stripVersion finds the version segment after a versioned prefix of a
+// path, e.g. v2 in /api/v2/users. Only paths of versioned routes have one:
+// /api/v2/auth/login is not an alias of an unversioned /api/auth/login.
+func (r *Router) stripVersion(set *routeSet, method, path string) (prefix, segment string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, prefix := range set.versionPrefixes {
+		if !strings.HasPrefix(path, prefix+"/v") {
+			continue
+		}
+		segment, _, _ := strings.Cut(path[len(prefix)+1:], "/")
+		if _, ok := parseVersion(segment); !ok {
+			continue
+		}
+		if set.isVersioned(method, withoutSegment(path, prefix, segment)) {
+			return prefix, segment, true
+		}
+	}
+	return "", "", false
+}
+
+// isVersioned reports whether a versioned route serves the method and path,
+// with HEAD served by GET; r.mu must be held
+func (set *routeSet) isVersioned(method, path string) bool {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	methods := []string{method}
+	if method == http.MethodHead {
+		methods = append(methods, http.MethodGet)
+	}
+	for _, m := range methods {
+		if root := set.versionTrees[m]; root != nil {
+			if handler, _ := root.getValue(path); handler != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutSegment removes the version segment following prefix from a path
+func withoutSegment(path, prefix, segment string) string {
+	path = path[:len(prefix)] + path[len(prefix)+1+len(segment):]
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// removeVersionSegment rewrites a request URL to the path without its
+// version segment
+func removeVersionSegment(u *url.URL, prefix, segment string) {
+	u.Path = withoutSegment(u.Path, prefix, segment)
+	if u.RawPath != "" {
+		if strings.HasPrefix(u.RawPath, prefix+"/"+segment) {
+			u.RawPath = withoutSegment(u.RawPath, prefix, segment)
+		} else {
+			u.RawPath = ""
+		}
+	}
+}
+
+// Deprecation describes a deprecated route. Responses carry a Deprecation
+// header (RFC 9745), a Sunset header (RFC 8594) if the removal date is
+// known, and a Link to the migration guide.
+type Deprecation struct {
+	Since  time.Time `json:"since"`
+	Sunset time.Time `json:"sunset,omitzero"`
+	Link   string    `json:"link,omitempty"`
+}
+
+// setHeaders adds the deprecation headers to a response
+func (d *Deprecation) setHeaders(header http.Header) {
+	header.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+	}
+}
+
+// routeState holds what may change on a route after it was registered
+type routeState struct {
+	deprecation atomic.Pointer[Deprecation]
+}
+
+// Deprecated marks the route as deprecated. Without a Since date the route
+// counts as deprecated from now on. Uses are counted per client, see
+// Router.DeprecatedUsage.
+//
+//	api.Version("1").GET("/users", listUsers).Deprecated(router.Deprecation{
+//		Sunset: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+//		Link:   "https://example.com/docs/api/v2-migration",
+//	})
+func (rt *Route) Deprecated(d Deprecation) *Route {
+	if rt.router == nil {
+		panic("route is not registered")
+	}
+	if d.Since.IsZero() {
+		d.Since = time.Now()
+	}
+
+	r := rt.router
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rt.Deprecation = &d
+	rt.state.deprecation.Store(&d)
+	return rt
+}
+
+// Deprecated marks the routes registered on the group from now on as
+// deprecated, e.g. all routes of an old API version
+func (g *RouterGroup) Deprecated(d Deprecation) *RouterGroup {
+	if d.Since.IsZero() {
+		d.Since = time.Now()
+	}
+	g.deprecation = &d
+	return g
+}
+
+// deprecationHandler sends the deprecation headers of a deprecated route and
+// counts its uses
+func (r *Router) deprecationHandler(route *Route, next HandlerFunc) HandlerFunc {
+	state := route.state
+	return func(c *Context) error {
+		if d := state.deprecation.Load(); d != nil {
+			d.setHeaders(c.Writer.Header())
+			r.countDeprecatedUse(route, deprecationClient(c))
+		}
+		return next(c)
+	}
+}
+
+// DeprecatedUse counts the requests a client made to a deprecated route
+type DeprecatedUse struct {
+	Method   string    `json:"method"`
+	Host     string    `json:"host,omitempty"`
+	Path     string    `json:"path"`
+	Version  string    `json:"version,omitempty"`
+	Client   string    `json:"client"`
+	Count    int64     `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// deprecatedUseKey identifies the uses of a route by a client
+type deprecatedUseKey struct {
+	route  *Route
+	client string
+}
+
+// deprecatedUseCount counts the uses of a route by a client
+type deprecatedUseCount struct {
+	count    atomic.Int64
+	lastUsed atomic.Int64 // unix nanoseconds
+}
+
+// countDeprecatedUse counts a use of a deprecated route
+func (r *Router) countDeprecatedUse(route *Route, client string) {
+	key := deprecatedUseKey{route: route, client: client}
+	value, ok := r.deprecatedUses.Load(key)
+	if !ok {
+		value, _ = r.deprecatedUses.LoadOrStore(key, &deprecatedUseCount{})
+	}
+	counter := value.(*deprecatedUseCount)
+	counter.count.Add(1)
+	counter.lastUsed.Store(time.Now().UnixNano())
+}
+
+// deprecationClient identifies the client of a request by its API key. Keys
+// are hashed so that they can be logged.
+func deprecationClient(c *Context) string {
+	key := c.Header("X-Api-Key")
+	if key == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "key:" + hex.EncodeToString(sum[:6])
+}
+
+// DeprecatedUsage returns how often each client used the deprecated routes
+// of the router, busiest first
+func (r *Router) DeprecatedUsage() []DeprecatedUse {
+	uses := []DeprecatedUse{}
+	r.deprecatedUses.Range(func(k, v any) bool {
+		key := k.(deprecatedUseKey)
+		counter := v.(*deprecatedUseCount)
+		uses = append(uses, DeprecatedUse{
+			Method:   key.route.Method,
+			Host:     key.route.Host,
+			Path:     key.route.Path,
+			Version:  key.route.Version,
+			Client:   key.client,
+			Count:    counter.count.Load(),
+			LastUsed: time.Unix(0, counter.lastUsed.Load()),
+		})
+		return true
+	})
+	sort.Slice(uses, func(i, j int) bool {
+		if uses[i].Count != uses[j].Count {
+			return uses[i].Count > uses[j].Count
+		}
+		if uses[i].Path != uses[j].Path {
+			return uses[i].Path < uses[j].Path
+		}
+		return uses[i].Client < uses[j].Client
+	})
+	return uses
+}
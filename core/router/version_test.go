@@ -0,0 +1,64 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// chromeAccept is the Accept header Chrome sends when navigating to a page
+const chromeAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"
+
+func TestVersionSelection(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.Version("1").GET("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "v%s", c.Version())
+	})
+	api.Version("2").GET("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "v%s", c.Version())
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		header  string // Accept-Version
+		accept  string
+		status  int
+		version string // "" when no version serves the request
+	}{
+		{name: "no version", path: "/api/users", status: http.StatusOK, version: "1"},
+		{name: "browser navigation", path: "/api/users", accept: chromeAccept, status: http.StatusOK, version: "1"},
+		{name: "path", path: "/api/v2/users", status: http.StatusOK, version: "2"},
+		{name: "header", path: "/api/users", header: "2", status: http.StatusOK, version: "2"},
+		{name: "invalid header", path: "/api/users", header: "two", status: http.StatusBadRequest},
+		{name: "vendor media type", path: "/api/users", accept: "application/vnd.base.v2+json", status: http.StatusOK, version: "2"},
+		{name: "json version parameter", path: "/api/users", accept: "application/json; version=2", status: http.StatusOK, version: "2"},
+		{name: "version parameter of another media type", path: "/api/users", accept: "text/html; version=2", status: http.StatusOK, version: "1"},
+		{name: "v parameter", path: "/api/users", accept: "application/json; v=2", status: http.StatusOK, version: "1"},
+		{name: "unparseable version parameter", path: "/api/users", accept: "application/json; version=beta, application/vnd.base.v2+json", status: http.StatusOK, version: "2"},
+		{name: "newer than registered", path: "/api/users", header: "3.1", status: http.StatusOK, version: "2"},
+		{name: "older than registered", path: "/api/users", header: "0.9", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(VersionHeader, tt.header)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.version != "" && rec.Body.String() != "v"+tt.version {
+				t.Errorf("served %q, want version %s", rec.Body.String(), tt.version)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Commands returns the scheduler management commands
+func (m *Module) Commands() []*cobra.Command {
+	return []*cobra.Command{
+		m.runCommand(),
+		m.listCommand(),
+	}
+}
+
+// runCommand runs a registered task once, outside of its schedule
+func (m *Module) runCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scheduler:run <task>",
+		Short: "Run a scheduled task now",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if _, exists := m.Scheduler.GetTask(name); exists {
+				if err := m.Scheduler.RunTaskNow(name); err != nil {
+					return err
+				}
+			} else if _, exists := m.CronScheduler.GetTask(name); exists {
+				if err := m.CronScheduler.RunTaskNow(name); err != nil {
+					return err
+				}
+			} else {
+				return fmt.Errorf("task %s not found", name)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ Task %s completed\n", name)
+			return nil
+		},
+	}
+}
+
+// listCommand lists the tasks registered by modules
+func (m *Module) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scheduler:list",
+		Short: "List scheduled tasks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSCHEDULE\tENABLED\tDESCRIPTION")
+
+			tasks := m.Scheduler.GetAllTasks()
+			names := make([]string, 0, len(tasks))
+			for name := range tasks {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				task := tasks[name]
+				fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", task.Name, task.Schedule.String(), task.Enabled, task.Description)
+			}
+
+			cronTasks := m.CronScheduler.GetAllTasks()
+			sort.Slice(cronTasks, func(i, j int) bool { return cronTasks[i].Name < cronTasks[j].Name })
+			for _, task := range cronTasks {
+				fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", task.Name, task.CronExpr, task.Enabled, task.Description)
+			}
+
+			return w.Flush()
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"mime/multipart"
 	"os"
@@ -105,7 +106,7 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 	}
 
 	// Upload file using provider
-	result, err := as.provider.Upload(file, UploadConfig{
+	result, err := as.upload(file, UploadConfig{
 		AllowedExtensions: config.AllowedExtensions,
 		MaxFileSize:       config.MaxFileSize,
 		UploadPath:        filepath.Join(config.Path, model.GetModelName(), field),
@@ -120,8 +121,9 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 
 	// Save attachment record
 	if err := as.db.Create(attachment).Error; err != nil {
-		// Try to delete uploaded file if record creation fails
-		_ = as.provider.Delete(result.Path)
+		// Try to delete uploaded file if record creation fails, even when
+		// the context is done
+		_ = as.WithContext(context.WithoutCancel(as.currentContext())).remove(result.Path)
 		return nil, err
 	}
 
@@ -129,12 +131,48 @@ func (as *ActiveStorage) Attach(model Attachable, field string, file *multipart.
 }
 
 func (as *ActiveStorage) Delete(attachment *Attachment) error {
-	if err := as.provider.Delete(attachment.Path); err != nil {
+	if err := as.remove(attachment.Path); err != nil {
 		return err
 	}
 	return as.db.Delete(attachment).Error
 }
 
+// WithContext returns a copy of the storage whose database queries and
+// provider calls use ctx, e.g. a request's Context() so that they are
+// cancelled with it:
+//
+//	attachment, err := s.activeStorage.WithContext(ctx).Attach(user, "avatar", file)
+func (as *ActiveStorage) WithContext(ctx context.Context) *ActiveStorage {
+	clone := *as
+	clone.db = as.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// currentContext returns the context set by WithContext
+func (as *ActiveStorage) currentContext() context.Context {
+	if as.ctx == nil {
+		return context.Background()
+	}
+	return as.ctx
+}
+
+// upload uploads a file, with the context if the provider supports it
+func (as *ActiveStorage) upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
+	if provider, ok := as.provider.(ContextProvider); ok {
+		return provider.UploadContext(as.currentContext(), file, config)
+	}
+	return as.provider.Upload(file, config)
+}
+
+// remove deletes a file, with the context if the provider supports it
+func (as *ActiveStorage) remove(path string) error {
+	if provider, ok := as.provider.(ContextProvider); ok {
+		return provider.DeleteContext(as.currentContext(), path)
+	}
+	return as.provider.Delete(path)
+}
+
 func (as *ActiveStorage) getConfig(modelName, field string) (AttachmentConfig, error) {
 	modelConfigs, ok := as.configs[modelName]
 	if !ok {
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// gcBatchSize is the number of attachment records inspected per query
+const gcBatchSize = 500
+
+// GCResult summarizes a garbage collection run
+type GCResult struct {
+	Scanned int
+	Orphans []Attachment
+	// Skipped counts attachments whose owner table or field could not be
+	// checked, e.g. because the model type does not map to a table
+	Skipped int
+	Errors  []error
+}
+
+// GarbageCollect removes attachments that no record references anymore: the
+// owning row is gone, or its attachment column is empty or points to another
+// attachment (the file was replaced). Owners stored through a polymorphic
+// relation have no column and are only checked for existence. With dryRun the
+// orphans are reported but neither the files nor the records are deleted.
+func (as *ActiveStorage) GarbageCollect(dryRun bool) (*GCResult, error) {
+	result := &GCResult{}
+	var lastId uint
+
+	for {
+		var batch []Attachment
+		if err := as.db.Where("id > ?", lastId).Order("id").Limit(gcBatchSize).Find(&batch).Error; err != nil {
+			return result, fmt.Errorf("failed to list attachments: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		lastId = batch[len(batch)-1].Id
+
+		for _, attachment := range batch {
+			result.Scanned++
+
+			orphan, checked := as.isOrphan(attachment)
+			if !checked {
+				result.Skipped++
+				continue
+			}
+			if !orphan {
+				continue
+			}
+
+			result.Orphans = append(result.Orphans, attachment)
+			if dryRun {
+				continue
+			}
+			// A file that is already gone should not keep its record alive
+			if err := as.provider.Delete(attachment.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				result.Errors = append(result.Errors, fmt.Errorf("attachment %d (%s): %w", attachment.Id, attachment.Path, err))
+				continue
+			}
+			if err := as.db.Delete(&attachment).Error; err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("attachment %d: %w", attachment.Id, err))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// isOrphan reports whether no record references the attachment. checked is
+// false when the owner could not be inspected.
+func (as *ActiveStorage) isOrphan(attachment Attachment) (orphan bool, checked bool) {
+	migrator := as.db.Migrator()
+	if attachment.ModelType == "" || !migrator.HasTable(attachment.ModelType) {
+		return false, false
+	}
+
+	// Polymorphic owners only need to exist
+	if attachment.Field == "" || !migrator.HasColumn(attachment.ModelType, attachment.Field) {
+		var count int64
+		if err := as.db.Table(attachment.ModelType).Where("id = ?", attachment.ModelId).Count(&count).Error; err != nil {
+			return false, false
+		}
+		return count == 0, true
+	}
+
+	var value sql.NullString
+	err := as.db.Table(attachment.ModelType).
+		Select(as.db.Statement.Quote(attachment.Field)).
+		Where("id = ?", attachment.ModelId).
+		Row().
+		Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, true
+	}
+	if err != nil {
+		return false, false
+	}
+	if !value.Valid || value.String == "" || value.String == "null" {
+		return true, true
+	}
+
+	var current Attachment
+	if err := json.Unmarshal([]byte(value.String), &current); err != nil {
+		return false, false
+	}
+	return current.Id != attachment.Id, true
+}
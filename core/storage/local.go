@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -32,6 +33,14 @@ func NewLocalProvider(config LocalConfig) (Provider, error) {
 }
 
 func (p *localProvider) Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
+	return p.UploadContext(context.Background(), file, config)
+}
+
+func (p *localProvider) UploadContext(ctx context.Context, file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Create upload directory
 	uploadPath := filepath.Join(p.basePath, config.UploadPath)
 	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
@@ -71,6 +80,13 @@ func (p *localProvider) Upload(file *multipart.FileHeader, config UploadConfig)
 }
 
 func (p *localProvider) Delete(path string) error {
+	return p.DeleteContext(context.Background(), path)
+}
+
+func (p *localProvider) DeleteContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	fullPath := filepath.Join(p.basePath, path)
 	return os.Remove(fullPath)
 }
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"mime/multipart"
 	"strings"
@@ -59,6 +60,10 @@ func NewR2Provider(config R2Config) (Provider, error) {
 }
 
 func (p *r2Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
+	return p.UploadContext(context.Background(), file, config)
+}
+
+func (p *r2Provider) UploadContext(ctx context.Context, file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
 	// Open source file
 	src, err := file.Open()
 	if err != nil {
@@ -71,7 +76,7 @@ func (p *r2Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*U
 	key := fmt.Sprintf("%s/%s", config.UploadPath, filename)
 
 	// Upload to R2
-	_, err = p.client.PutObject(&s3.PutObjectInput{
+	_, err = p.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(p.bucket),
 		Key:         aws.String(key),
 		Body:        src,
@@ -90,7 +95,11 @@ func (p *r2Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*U
 }
 
 func (p *r2Provider) Delete(path string) error {
-	_, err := p.client.DeleteObject(&s3.DeleteObjectInput{
+	return p.DeleteContext(context.Background(), path)
+}
+
+func (p *r2Provider) DeleteContext(ctx context.Context, path string) error {
+	_, err := p.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(p.bucket),
 		Key:    aws.String(path),
 	})
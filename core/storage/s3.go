@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"mime/multipart"
 
@@ -55,6 +56,10 @@ func NewS3Provider(config S3Config) (Provider, error) {
 }
 
 func (p *s3Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
+	return p.UploadContext(context.Background(), file, config)
+}
+
+func (p *s3Provider) UploadContext(ctx context.Context, file *multipart.FileHeader, config UploadConfig) (*UploadResult, error) {
 	// Open source file
 	src, err := file.Open()
 	if err != nil {
@@ -67,7 +72,7 @@ func (p *s3Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*U
 	key := fmt.Sprintf("%s/%s", config.UploadPath, filename)
 
 	// Upload to S3
-	_, err = p.client.PutObject(&s3.PutObjectInput{
+	_, err = p.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(p.bucket),
 		Key:    aws.String(key),
 		Body:   src,
@@ -85,7 +90,11 @@ func (p *s3Provider) Upload(file *multipart.FileHeader, config UploadConfig) (*U
 }
 
 func (p *s3Provider) Delete(path string) error {
-	_, err := p.client.DeleteObject(&s3.DeleteObjectInput{
+	return p.DeleteContext(context.Background(), path)
+}
+
+func (p *s3Provider) DeleteContext(ctx context.Context, path string) error {
+	_, err := p.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(p.bucket),
 		Key:    aws.String(path),
 	})
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
@@ -106,12 +107,21 @@ type Provider interface {
 	GetURL(path string) string
 }
 
+// ContextProvider is implemented by providers whose uploads and deletes can
+// be cancelled, e.g. when a request's deadline passes
+type ContextProvider interface {
+	Provider
+	UploadContext(ctx context.Context, file *multipart.FileHeader, config UploadConfig) (*UploadResult, error)
+	DeleteContext(ctx context.Context, path string) error
+}
+
 // ActiveStorage handles file storage operations
 type ActiveStorage struct {
 	db          *gorm.DB
 	provider    Provider
 	defaultPath string
 	configs     map[string]map[string]AttachmentConfig
+	ctx         context.Context // set by WithContext
 }
 
 // UploadConfig holds configuration for file uploads
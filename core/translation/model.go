@@ -245,7 +245,7 @@ type CreateTranslationRequest struct {
 
 // UpdateTranslationRequest represents the request payload for updating a Translation
 type UpdateTranslationRequest struct {
-	Id       uint   `json:"id" binding:"required"`
+	Id       uint   `json:"id"` // Set from the path
 	Key      string `json:"key,omitempty"`
 	Value    string `json:"value,omitempty"`
 	Model    string `json:"model,omitempty"`
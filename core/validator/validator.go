@@ -35,18 +35,27 @@ func (v ValidationErrors) Error() string {
 
 // New creates a new validator instance
 func New() *Validator {
+	return NewWithTag("validate")
+}
+
+// NewWithTag creates a validator that reads its rules from the given struct
+// tag, e.g. "binding" for request DTOs
+func NewWithTag(tag string) *Validator {
 	v := validator.New()
+	v.SetTagName(tag)
 
-	// Register custom tag name function to use json tags for field names
+	// Register custom tag name function to use json (or form) tags for field names
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
+		for _, key := range []string{"json", "form"} {
+			name := strings.SplitN(fld.Tag.Get(key), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
 		}
-		if name == "" {
-			return fld.Name
-		}
-		return name
+		return fld.Name
 	})
 
 	return &Validator{validate: v}
@@ -65,7 +74,7 @@ func (v *Validator) Validate(data interface{}) ValidationErrors {
 	if validatorErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, err := range validatorErrors {
 			validationErrors = append(validationErrors, ValidationError{
-				Field:   err.Field(),
+				Field:   fieldPath(err),
 				Tag:     err.Tag(),
 				Value:   fmt.Sprintf("%v", err.Value()),
 				Message: v.getErrorMessage(err),
@@ -76,6 +85,16 @@ func (v *Validator) Validate(data interface{}) ValidationErrors {
 	return validationErrors
 }
 
+// fieldPath returns the path of a field below the validated struct, e.g.
+// "address.city" or "items[0].name"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
 // ValidateVar validates a single variable
 func (v *Validator) ValidateVar(field interface{}, tag string) ValidationErrors {
 	err := v.validate.Var(field, tag)
@@ -100,7 +119,7 @@ func (v *Validator) ValidateVar(field interface{}, tag string) ValidationErrors
 
 // getErrorMessage returns a user-friendly error message for a validation error
 func (v *Validator) getErrorMessage(fe validator.FieldError) string {
-	field := fe.Field()
+	field := fieldPath(fe)
 	tag := fe.Tag()
 	param := fe.Param()
 
@@ -110,11 +129,11 @@ func (v *Validator) getErrorMessage(fe validator.FieldError) string {
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", field)
 	case "min":
-		return fmt.Sprintf("%s must be at least %s characters long", field, param)
+		return fmt.Sprintf("%s must be at least %s%s", field, param, lengthUnit(fe))
 	case "max":
-		return fmt.Sprintf("%s must be at most %s characters long", field, param)
+		return fmt.Sprintf("%s must be at most %s%s", field, param, lengthUnit(fe))
 	case "len":
-		return fmt.Sprintf("%s must be exactly %s characters long", field, param)
+		return fmt.Sprintf("%s must be exactly %s%s", field, param, lengthUnit(fe))
 	case "numeric":
 		return fmt.Sprintf("%s must be a number", field)
 	case "alpha":
@@ -140,13 +159,28 @@ func (v *Validator) getErrorMessage(fe validator.FieldError) string {
 	}
 }
 
+// lengthUnit describes what min, max and len count for the field's kind
+func lengthUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
 // RegisterValidation adds a custom validation rule
 func (v *Validator) RegisterValidation(tag string, fn validator.Func) error {
 	return v.validate.RegisterValidation(tag, fn)
 }
 
-// Global validator instance
-var defaultValidator = New()
+// Global validator instances
+var (
+	defaultValidator = New()
+	bindingValidator = NewWithTag("binding")
+)
 
 // Validate validates using the default validator instance
 func Validate(data interface{}) ValidationErrors {
@@ -157,3 +191,8 @@ func Validate(data interface{}) ValidationErrors {
 func ValidateVar(field interface{}, tag string) ValidationErrors {
 	return defaultValidator.ValidateVar(field, tag)
 }
+
+// ValidateBinding validates a request DTO using its `binding` tags
+func ValidateBinding(data interface{}) ValidationErrors {
+	return bindingValidator.Validate(data)
+}
@@ -2,10 +2,12 @@ package websocket
 
 import (
 	"base/core/router"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -42,6 +44,9 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mutex      *sync.Mutex
+	quit       chan struct{}
+	done       chan struct{}
+	closeOnce  sync.Once
 }
 
 // NewHub creates a new Hub instance
@@ -52,13 +57,21 @@ func NewHub() *Hub {
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		mutex:      &sync.Mutex{},
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
 	}
 }
 
 // Run starts the Hub
 func (h *Hub) Run() {
+	defer close(h.done)
+
 	for {
 		select {
+		case <-h.quit:
+			h.closeAll()
+			return
+
 		case client := <-h.register:
 			h.mutex.Lock()
 			if _, ok := h.rooms[client.Room]; !ok {
@@ -179,7 +192,11 @@ func (h *Hub) Run() {
 
 func (c *Client) readPump(hub *Hub) {
 	defer func() {
-		hub.unregister <- c
+		// The hub may already be stopped during shutdown
+		select {
+		case hub.unregister <- c:
+		case <-hub.done:
+		}
 		c.Conn.Close()
 	}()
 
@@ -221,7 +238,11 @@ func (c *Client) readPump(hub *Hub) {
 				}
 			} else {
 				// For other messages, use the general broadcast channel
-				hub.broadcast <- msgBytes
+				select {
+				case hub.broadcast <- msgBytes:
+				case <-hub.done:
+					return
+				}
 			}
 		}
 	}
@@ -265,7 +286,12 @@ func ServeWs(hub *Hub, c *router.Context) {
 		Send:     make(chan []byte, 256),
 	}
 
-	hub.register <- client
+	select {
+	case hub.register <- client:
+	case <-hub.done:
+		conn.Close()
+		return
+	}
 
 	go client.writePump()
 	go client.readPump(hub)
@@ -279,7 +305,43 @@ func (h *Hub) BroadcastMessage(messageType string, content any) {
 		Nickname: "System",
 	}
 	if msgBytes, err := json.Marshal(message); err == nil {
-		h.broadcast <- msgBytes
+		select {
+		case h.broadcast <- msgBytes:
+		case <-h.done:
+		}
+	}
+}
+
+// Shutdown stops the hub and closes all client connections with a
+// going-away close frame. It blocks until the hub loop has exited or
+// the context is done.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.closeOnce.Do(func() {
+		close(h.quit)
+	})
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closeAll notifies and disconnects every client in every room
+func (h *Hub) closeAll() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	deadline := time.Now().Add(time.Second)
+
+	for room, clients := range h.rooms {
+		for client := range clients {
+			client.Conn.WriteControl(websocket.CloseMessage, closeMsg, deadline)
+			close(client.Send)
+		}
+		delete(h.rooms, room)
 	}
 }
 
@@ -3,10 +3,12 @@ package main
 import (
 	appmodules "base/api"
 	coremodules "base/core/app"
+	"base/core/app/authorization"
 	"base/core/config"
 	"base/core/database"
 	"base/core/email"
 	"base/core/emitter"
+	basehttp "base/core/http"
 	"base/core/logger"
 	"base/core/module"
 	"base/core/router"
@@ -15,13 +17,20 @@ import (
 	_ "base/core/translation"
 	"base/core/websocket"
 	_ "base/docs" // swagger docs
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"net"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"gorm.io/gorm"
 )
@@ -59,14 +68,21 @@ type App struct {
 	config      *config.Config
 	db          *database.Database
 	router      *router.Router
+	server      *router.Server
 	logger      logger.Logger
 	emitter     *emitter.Emitter
 	storage     *storage.ActiveStorage
+	idempotency *middleware.GormIdempotencyStore
 	emailSender email.Sender
 	wsHub       *websocket.Hub
+	initializer *module.Initializer
 
 	// State
-	running bool
+	bootstrapped  bool
+	stopReports   chan struct{}
+	running       bool
+	manualMigrate bool
+	manualSeed    bool
 }
 
 // New creates a new Base application instance
@@ -76,15 +92,119 @@ func New() *App {
 
 // Start initializes and starts the application
 func (app *App) Start() error {
-	return app.
-		loadEnvironment().
+	return app.bootstrap().serve()
+}
+
+// Execute runs the command given by args: no command starts the server,
+// otherwise one of the management commands contributed by modules runs
+// against the same dependencies without starting the HTTP listener. The
+// database and modules are only set up for commands that use them, not for
+// help or mistyped commands.
+func (app *App) Execute(args []string) error {
+	app.configure()
+
+	root := &cobra.Command{
+		Use:           "base",
+		Short:         "Base Framework application",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.serve()
+		},
+	}
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if needsDependencies(cmd) {
+			app.initDependencies(cmd == root)
+		}
+		return nil
+	}
+
+	root.AddCommand(module.MigrateCommand(app.initializer))
+	root.AddCommand(module.SeedCommand(app.initializer))
+	root.AddCommand(module.RoutesCommand(app.router))
+
+	// Module commands are listed before the modules are initialized and run
+	// the commands of the initialized modules, after PersistentPreRunE. The
+	// server does not need them.
+	if len(args) > 0 {
+		for _, cmd := range app.describeModuleCommands() {
+			root.AddCommand(module.LazyCommand(cmd, app.initializer.Commands))
+		}
+	}
+
+	root.SetArgs(args)
+	cmd, err := root.ExecuteC()
+	if cmd != root && app.db != nil {
+		// Management commands do not go through Stop
+		if closeErr := app.db.Close(); closeErr != nil {
+			app.logger.Error("Database close failed", logger.String("error", closeErr.Error()))
+		}
+	}
+	return err
+}
+
+// needsDependencies reports whether a command runs against the application's
+// dependencies, unlike cobra's help and completion commands
+func needsDependencies(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		switch cmd.Name() {
+		case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	return true
+}
+
+// describeModuleCommands returns the management commands of the core and app
+// modules, described by zero modules without setting up any dependency
+func (app *App) describeModuleCommands() []*cobra.Command {
+	commanders := coremodules.NewCoreModules().GetCoreCommanders()
+	maps.Copy(commanders, appmodules.NewAppModules().GetAppCommanders())
+	return module.DescribeCommands(commanders)
+}
+
+// bootstrap initializes configuration, infrastructure and modules
+func (app *App) bootstrap() *App {
+	return app.configure().initDependencies(true)
+}
+
+// configure loads the configuration and creates the logger, the router and
+// the module initializer, none of which touch the infrastructure
+func (app *App) configure() *App {
+	app.loadEnvironment().
 		initConfig().
-		initLogger().
+		initLogger()
+
+	app.router = router.New()
+	// Core and app modules share one initializer so they share one lifecycle
+	app.initializer = module.NewInitializer(app.logger)
+	return app
+}
+
+// initDependencies connects the infrastructure and initializes the modules,
+// once. Modules migrate and seed automatically only when the server runs;
+// management commands leave that to the migrate and seed commands.
+func (app *App) initDependencies(serving bool) *App {
+	if app.bootstrapped {
+		return app
+	}
+	app.bootstrapped = true
+	app.manualMigrate = !serving
+	app.manualSeed = !serving
+
+	return app.
 		initDatabase().
 		initInfrastructure().
 		initRouter().
 		autoDiscoverModules().
-		setupRoutes().
+		setupRoutes()
+}
+
+// serve starts the modules and the HTTP server
+func (app *App) serve() error {
+	return app.
+		startModules().
 		displayServerInfo().
 		run()
 }
@@ -159,6 +279,17 @@ func (app *App) initInfrastructure() *App {
 	}
 	app.storage = activeStorage
 
+	// Initialize the idempotency key store (non-fatal)
+	if app.config.Middleware.IdempotencyEnabled {
+		store, err := middleware.NewGormIdempotencyStore(app.db.DB, app.config.Middleware.GetIdempotencyTTL())
+		if err != nil {
+			app.logger.Warn("Idempotency store initialization failed - continuing without idempotency keys",
+				logger.String("error", err.Error()))
+		} else {
+			app.idempotency = store
+		}
+	}
+
 	// Initialize email sender (non-fatal)
 	emailSender, err := email.NewSender(app.config)
 	if err != nil {
@@ -175,7 +306,6 @@ func (app *App) initInfrastructure() *App {
 
 // initRouter initializes the router with middleware
 func (app *App) initRouter() *App {
-	app.router = router.New()
 	app.setupMiddleware()
 	app.setupStaticRoutes()
 	app.initWebSocket()
@@ -187,7 +317,16 @@ func (app *App) initRouter() *App {
 // setupMiddleware configures all middleware using the new configurable system
 func (app *App) setupMiddleware() {
 	// Apply configurable middleware system
-	middleware.ApplyConfigurableMiddleware(app.router, &app.config.Middleware)
+	cm := middleware.ApplyConfigurableMiddleware(app.router, &app.config.Middleware)
+
+	// Default request limits; routes pass router.Timeout or router.BodyLimit
+	// to override them, e.g. for uploads
+	if app.config.HandlerTimeout > 0 {
+		app.router.Use(router.Timeout(app.config.HandlerTimeout))
+	}
+	if app.config.MaxBodySize > 0 {
+		app.router.Use(router.BodyLimit(app.config.MaxBodySize))
+	}
 
 	// Custom request logging middleware (conditional based on config)
 	app.router.Use(func(next router.HandlerFunc) router.HandlerFunc {
@@ -217,14 +356,30 @@ func (app *App) setupMiddleware() {
 	// CORS middleware (conditional based on config)
 	if app.config.Middleware.CORSEnabled {
 		corsOrigins := strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",")
-		app.router.Use(middleware.CORSMiddleware(corsOrigins))
+		// Preflight requests are answered by the router's automatic OPTIONS
+		// responses, which run this middleware too
+		app.router.Use(cm.ConditionalCORS(corsOrigins))
+	}
 
-		// Add a catch-all OPTIONS handler for preflight requests
-		// This ensures OPTIONS requests don't 404 even if no explicit OPTIONS route exists
-		app.router.OPTIONS("/*catchall", func(c *router.Context) error {
-			// CORS headers are already set by the middleware above
-			return c.NoContent()
-		})
+	// Compression wraps the ETag middleware so that ETags describe the
+	// uncompressed body and stay the same for every encoding
+	if app.config.Middleware.CompressionEnabled {
+		compressConfig := middleware.DefaultCompressConfig()
+		compressConfig.MinLength = app.config.Middleware.CompressionMinLength
+		app.router.Use(middleware.Compress(compressConfig))
+	}
+	if app.config.Middleware.ETagEnabled {
+		app.router.Use(middleware.ConditionalGET(nil))
+	}
+
+	// Retried requests with an Idempotency-Key get the stored response; this
+	// runs after authentication so that keys are scoped to the user
+	if app.idempotency != nil {
+		idempotencyConfig := middleware.DefaultIdempotencyConfig(app.idempotency)
+		if app.config.MaxBodySize > 0 {
+			idempotencyConfig.MaxBodySize = app.config.MaxBodySize
+		}
+		app.router.Use(middleware.Idempotency(idempotencyConfig))
 	}
 }
 
@@ -237,25 +392,32 @@ func (app *App) setupStaticRoutes() {
 }
 
 // setupSPARoutes configures SPA (Single Page Application) serving
-// Serves Vue app from public/ directory
-// - Static assets (JS, CSS, images): served directly
-// - All other routes: serve index.html (SPA routing)
+// Serves the Vue app from public/, embedded in the binary when built with
+// -tags embedspa (see public_embed.go)
+// - Fingerprinted assets under /assets: cached as immutable
+// - All other routes: serve the file if it exists, otherwise index.html (SPA routing)
+// With SPA_DEV_SERVER set, e.g. to the Vite dev server, requests are proxied
+// there instead.
 func (app *App) setupSPARoutes() {
-	spaHandler := func(c *router.Context) error {
-		reqPath := c.Request.URL.Path
-
-		// Try to serve file (for assets like JS, CSS, images)
-		filePath := "./public" + reqPath
-
-		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
-			// File exists and is not a directory - serve it
-			c.File(filePath)
-			return nil
+	public := publicFS()
+	spaHandler := router.SPAHandler(public)
+
+	if app.config.SPADevServer != "" {
+		proxy, err := router.DevProxy(app.config.SPADevServer)
+		if err != nil {
+			app.logger.Error("Invalid SPA dev server, serving public/ instead", logger.String("error", err.Error()))
+		} else {
+			app.logger.Info("Proxying SPA requests to dev server", logger.String("url", app.config.SPADevServer))
+			spaHandler = proxy
 		}
+	}
 
-		// File doesn't exist or is a directory - serve index.html for SPA routing
-		c.File("./public/index.html")
-		return nil
+	// Vite build assets go through the router so that global middleware
+	// such as compression applies
+	if app.config.SPADevServer == "" {
+		if assets, err := fs.Sub(public, "assets"); err == nil {
+			app.router.StaticFS("/assets", assets, router.WithImmutable(router.Fingerprinted))
+		}
 	}
 
 	// Root path
@@ -289,6 +451,9 @@ func (app *App) initWebSocket() {
 
 // autoDiscoverModules automatically discovers and registers modules
 func (app *App) autoDiscoverModules() *App {
+	app.initializer.SetAutoMigrate(!app.manualMigrate)
+	app.initializer.SetAutoSeed(!app.manualSeed)
+
 	app.registerCoreModules()
 	app.discoverAndRegisterAppModules()
 
@@ -307,16 +472,17 @@ func (app *App) registerCoreModules() {
 		Storage:     app.storage,
 		EmailSender: app.emailSender,
 		Config:      app.config,
+		Services:    app.initializer.Services(),
 	}
 
 	// Initialize core modules via orchestrator to ensure proper init/migrate/routes
-	initializer := module.NewInitializer(app.logger)
 	coreProvider := coremodules.NewCoreModules()
-	orchestrator := module.NewCoreOrchestrator(initializer, coreProvider)
+	orchestrator := module.NewCoreOrchestrator(app.initializer, coreProvider)
 
 	initialized, err := orchestrator.InitializeCoreModules(deps)
 	if err != nil {
 		app.logger.Error("Failed to initialize core modules", logger.String("error", err.Error()))
+		panic(fmt.Sprintf("Core module initialization failed: %v", err))
 	}
 
 	app.logger.Info("✅ Core modules registered", logger.Int("count", len(initialized)))
@@ -333,6 +499,7 @@ func (app *App) discoverAndRegisterAppModules() {
 		Storage:     app.storage,
 		EmailSender: app.emailSender,
 		Config:      app.config,
+		Services:    app.initializer.Services(),
 	}
 
 	// Use app module provider (like core modules)
@@ -350,12 +517,27 @@ func (app *App) discoverAndRegisterAppModules() {
 
 // initializeModules initializes a collection of modules
 func (app *App) initializeModules(modules map[string]module.Module, deps module.Dependencies) {
-	initializer := module.NewInitializer(app.logger)
-	initializedModules := initializer.Initialize(modules, deps)
+	initializedModules, err := app.initializer.Initialize(modules, deps)
+	if err != nil {
+		app.logger.Error("Failed to initialize app modules", logger.String("error", err.Error()))
+		panic(fmt.Sprintf("App module initialization failed: %v", err))
+	}
 
 	app.logger.Info(fmt.Sprintf("✅ Module initialization complete : total: %d, initialized: %d", len(modules), len(initializedModules)))
 }
 
+// startModules starts background work for modules implementing module.Starter.
+// Called after every module has registered its routes.
+func (app *App) startModules() *App {
+	if err := app.initializer.Lifecycle().StartAll(context.Background()); err != nil {
+		app.logger.Error("Some modules failed to start", logger.String("error", err.Error()))
+		return app
+	}
+
+	app.logger.Info("✅ Modules started")
+	return app
+}
+
 // setupRoutes sets up basic system routes
 func (app *App) setupRoutes() *App {
 	// Health check
@@ -367,18 +549,47 @@ func (app *App) setupRoutes() *App {
 	})
 
 	// Swagger documentation
-	app.router.GET("/docs/*any", func(c *router.Context) error {
-		// Redirect /docs/ to /docs/index.html
-		if c.Request.URL.Path == "/docs/" || c.Request.URL.Path == "/docs" {
-			return c.Redirect(302, "/docs/index.html")
-		}
-		httpSwagger.Handler()(c.Writer, c.Request)
-		return nil
+	// Redirect /docs and /docs/ to /docs/index.html
+	app.router.GET("/docs", func(c *router.Context) error {
+		return c.Redirect(302, "/docs/index.html")
 	})
+	// The swagger handler resolves its files from the full request URI, so it
+	// is wrapped rather than mounted
+	app.router.GET("/docs/*any", router.WrapHandler(httpSwagger.Handler()))
 	app.router.GET("/swagger/*any", func(c *router.Context) error {
 		return c.Redirect(302, "/docs/index.html")
 	})
 
+	// Introspection endpoints map the API and its middleware, so only
+	// authenticated administrators reach them
+	admin := app.router.Group("/api",
+		authorization.ServiceMiddleware(authorization.NewAuthorizationService(app.db.DB)),
+		authorization.RequireRole("Administrator"),
+	)
+
+	// Effective middleware settings for a path, including module overrides
+	admin.GET("/middleware/effective", func(c *router.Context) error {
+		path := c.Query("path")
+		if path == "" || path[0] != '/' {
+			return c.JSON(400, map[string]string{"error": "path query parameter must be an absolute path"})
+		}
+		return c.JSON(200, app.config.Middleware.Effective(path))
+	})
+
+	// Registered routes with their names, modules and middleware
+	admin.GET("/routes", func(c *router.Context) error {
+		return c.JSON(200, app.router.Routes())
+	}).Named("routes.index")
+
+	// How often each client still uses deprecated routes
+	admin.GET("/routes/deprecated", func(c *router.Context) error {
+		return c.JSON(200, app.router.DeprecatedUsage())
+	}).Named("routes.deprecated")
+
+	// Several API calls in one round trip, each dispatched through the router
+	// with the caller's credentials
+	app.router.POST("/api/batch", app.router.Batch()).Named("batch")
+
 	return app
 }
 
@@ -415,31 +626,99 @@ func (app *App) getLocalIP() string {
 	return "localhost"
 }
 
-// run starts the HTTP server
+// run starts the HTTP server and blocks until it stops or a shutdown signal arrives
 func (app *App) run() error {
 	app.running = true
 	port := app.config.ServerPort
 
+	serverConfig := basehttp.DefaultServerConfig()
+	serverConfig.ReadTimeout = app.config.ServerReadTimeout
+	serverConfig.WriteTimeout = app.config.ServerWriteTimeout
+	serverConfig.IdleTimeout = app.config.ServerIdleTimeout
+	serverConfig.ShutdownTimeout = app.config.ShutdownTimeout
+	app.server = router.NewServer(app.router, serverConfig)
+
 	app.logger.Info(fmt.Sprintf("🌐 Server starting  on port %s", port))
 
-	err := app.router.Run(port)
-	if err != nil {
-		// Check if it's an "address already in use" error
-		if strings.Contains(err.Error(), "bind: address already in use") {
-			app.logger.Error("❌ Server failed to start - Port already in use",
-				logger.String("port", port),
-				logger.String("error", err.Error()))
-			return fmt.Errorf("port %s is already in use. Please:\n  • Stop any other servers running on this port\n  • Change the SERVER_PORT in your .env file\n  • Use a different port with: export SERVER_PORT=:8101", port)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.server.Start(port)
+	}()
+
+	app.stopReports = make(chan struct{})
+	go app.reportDeprecatedUsage(app.stopReports)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			err = app.serverFailure(port, err)
+		}
+		// Release modules, the hub and the database whether the server
+		// stopped or failed to start
+		if stopErr := app.Stop(); err == nil {
+			return stopErr
 		}
-		// For other network errors, provide a generic helpful message
-		app.logger.Error("❌ Server failed to start",
+		return err
+
+	case sig := <-quit:
+		app.logger.Info("Received shutdown signal", logger.String("signal", sig.String()))
+		return app.Stop()
+	}
+}
+
+// serverFailure logs why the server could not start and returns a helpful error
+func (app *App) serverFailure(port string, err error) error {
+	// Check if it's an "address already in use" error
+	if strings.Contains(err.Error(), "bind: address already in use") {
+		app.logger.Error("❌ Server failed to start - Port already in use",
+			logger.String("port", port),
 			logger.String("error", err.Error()))
-		return fmt.Errorf("server failed to start: %w", err)
+		return fmt.Errorf("port %s is already in use. Please:\n  • Stop any other servers running on this port\n  • Change the SERVER_PORT in your .env file\n  • Use a different port with: export SERVER_PORT=:8101", port)
 	}
-	return nil
+	// For other network errors, provide a generic helpful message
+	app.logger.Error("❌ Server failed to start",
+		logger.String("error", err.Error()))
+	return fmt.Errorf("server failed to start: %w", err)
 }
 
-// Graceful shutdown (future enhancement)
+// reportDeprecatedUsage logs the use of deprecated routes every hour until
+// done is closed
+func (app *App) reportDeprecatedUsage(done <-chan struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.logDeprecatedUsage()
+		case <-done:
+			return
+		}
+	}
+}
+
+// logDeprecatedUsage logs how often each client used the deprecated routes,
+// so that they can be removed once no client needs them
+func (app *App) logDeprecatedUsage() {
+	for _, use := range app.router.DeprecatedUsage() {
+		app.logger.Warn("Deprecated route in use",
+			logger.String("method", use.Method),
+			logger.String("path", use.Host+use.Path),
+			logger.String("version", use.Version),
+			logger.String("client", use.Client),
+			logger.Int64("count", use.Count),
+			logger.String("last_used", use.LastUsed.Format(time.RFC3339)))
+	}
+}
+
+// Stop gracefully shuts down the application. Components are stopped in
+// reverse order of their dependencies: the HTTP listener is drained first,
+// then modules (including the scheduler), the websocket hub and finally
+// the database pool.
 func (app *App) Stop() error {
 	if !app.running {
 		return nil
@@ -447,6 +726,65 @@ func (app *App) Stop() error {
 
 	app.logger.Info("🛑 Shutting down gracefully...")
 	app.running = false
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.config.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+
+	// Stop accepting new connections and drain in-flight requests
+	if app.server != nil {
+		if err := app.server.Shutdown(ctx); err != nil {
+			app.logger.Error("HTTP server shutdown failed", logger.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("http server: %w", err))
+		} else {
+			app.logger.Info("✅ HTTP server drained")
+		}
+	}
+
+	// Stop modules in reverse start order
+	if app.initializer != nil {
+		if err := app.initializer.Lifecycle().StopAll(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("modules: %w", err))
+		}
+	}
+
+	// Disconnect websocket clients
+	if app.wsHub != nil {
+		if err := app.wsHub.Shutdown(ctx); err != nil {
+			app.logger.Error("WebSocket hub shutdown failed", logger.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("websocket hub: %w", err))
+		} else {
+			app.logger.Info("✅ WebSocket hub closed")
+		}
+	}
+
+	if app.idempotency != nil {
+		app.idempotency.Stop()
+	}
+
+	// Report the deprecated routes used since the last report
+	if app.stopReports != nil {
+		close(app.stopReports)
+		app.stopReports = nil
+	}
+	app.logDeprecatedUsage()
+
+	// Close the database pool last so draining requests can still use it
+	if app.db != nil {
+		if err := app.db.Close(); err != nil {
+			app.logger.Error("Database close failed", logger.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("database: %w", err))
+		} else {
+			app.logger.Info("✅ Database connections closed")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("shutdown completed with errors: %w", errors.Join(errs...))
+	}
+
+	app.logger.Info("👋 Shutdown complete")
 	return nil
 }
 
@@ -455,8 +793,8 @@ func main() {
 	// Initialize the Base application
 	app := New()
 
-	// Normal application startup
-	if err := app.Start(); err != nil {
+	// Start the server or run a management command
+	if err := app.Execute(os.Args[1:]); err != nil {
 		// Print user-friendly error message instead of panicking
 		fmt.Printf("\n❌ Application failed to start:\n%v\n\n", err)
 		os.Exit(1)
@@ -0,0 +1,14 @@
+//go:build !embedspa
+
+package main
+
+import (
+	"io/fs"
+	"os"
+)
+
+// publicFS returns the built Vue app from the public/ directory. Build with
+// -tags embedspa to embed it in the binary instead.
+func publicFS() fs.FS {
+	return os.DirFS("./public")
+}
@@ -0,0 +1,23 @@
+//go:build embedspa
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedPublic holds the built Vue app; run the Vue build into public/
+// before building with -tags embedspa
+//
+//go:embed all:public
+var embeddedPublic embed.FS
+
+// publicFS returns the built Vue app embedded in the binary
+func publicFS() fs.FS {
+	public, err := fs.Sub(embeddedPublic, "public")
+	if err != nil {
+		panic(err)
+	}
+	return public
+}